@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/dep"
+)
+
+// evaluate parses the test set with the trained model, and prints a detailed evaluation
+func evaluate() {
+	p := dep.New(DepModel)
+	predicted := make([]*lingo.Dependency, len(testTB))
+	gold := make([]*lingo.Dependency, len(testTB))
+	for i, st := range testTB {
+		gold[i] = st.Dependency(fixer{})
+
+		var err error
+		if predicted[i], err = p.Parse(st.AnnotatedSentence(fixer{}), dep.WithBeam(*beam)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var opts []dep.EvalOpt
+	if *punct {
+		opts = append(opts, dep.WithPunctuation())
+	}
+	eval, err := dep.EvaluateDetailed(predicted, gold, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Evaluation:\n%v", eval)
+
+	if *report != "" {
+		bs, err := json.MarshalIndent(eval, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(*report, bs, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Evaluation report written to %v", *report)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io/ioutil"
 	"log"
 
 	"github.com/chewxy/lingo/dep"
@@ -29,6 +30,40 @@ func validateFlags() {
 		*cv = true
 	}
 
+	if ts, err := dep.ParseTransitionSystem(*system); err != nil {
+		log.Fatal(err)
+	} else if *joint && ts == dep.ArcEager {
+		log.Fatal("Joint tagging is not supported by ArcEager")
+	} else if *explore > 0 && ts == dep.ArcSwap {
+		log.Fatalf("%v has no dynamic oracle. -explore requires -system ArcStandard or ArcEager", ts)
+	}
+
+	if *joint && *folds > 0 {
+		log.Fatal("Cannot train with jackknifed POS tags when tagging jointly")
+	}
+
+	if _, err := dep.ParseEmbeddingFormat(*embeddingFormat); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := dep.ParseSolverType(*solver); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := dep.ParseDecayType(*decay); err != nil {
+		log.Fatal(err)
+	}
+
+	if *featureFile != "" {
+		b, err := ioutil.ReadFile(*featureFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if features, err = dep.ParseFeatureTemplates(string(b)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// warnings
 	if *load == "" && *save == "" {
 		log.Println("WARNING: Models that have been trained will NOT be saved")
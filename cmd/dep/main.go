@@ -21,6 +21,30 @@ var trainFile = flag.String("train", "", "Training on... (Only CONLLU formatted
 var testFile = flag.String("test", "", "Test on... (Only CONLLU formatted training files are accepted). If this is not provided, the model will be trained without crossvalidation")
 var cv = flag.Bool("cv", false, "Cross validate training model? Defaults to false.")
 var epoch = flag.Int("epoch", 10, "Training epochs. Defaults to 10")
+var system = flag.String("system", "ArcStandard", "Transition system to train with. Accepts: {ArcStandard, ArcEager, ArcSwap}")
+var explore = flag.Float64("explore", 0, "Probability of exploring the parser's own predictions when training with the dynamic oracle. ArcSwap has no dynamic oracle, so this requires -system ArcStandard or ArcEager. Defaults to 0, which trains with the static oracle")
+var joint = flag.Bool("joint", false, "Train a parser that also POS tags the words (ArcStandard and ArcSwap only). Defaults to false")
+var folds = flag.Int("jackknife", 0, "Train on POS tags predicted by taggers trained on this many folds of the training set. Defaults to 0, which trains on the gold tags")
+var foldIter = flag.Int("jackknifeIter", 10, "Training iterations of each jackknifing tagger. Defaults to 10")
+var featureFile = flag.String("features", "", "File of feature templates to train with, e.g. `word[s0] pos[s0.l1] suffix:3[b0]`. If nothing is passed in, the default Chen & Manning features are used")
+var embeddings = flag.String("embeddings", "", "Pretrained word embeddings to initialize the parser with. The size of the vectors must match the embedding size of the parser (EmbeddingSize in the config)")
+var embeddingFormat = flag.String("embeddingFormat", "word2vec", "Format of the pretrained word embeddings. Accepts: {word2vec, word2vec-binary, glove}")
+var addPretrained = flag.Bool("addPretrained", false, "Add the words of the pretrained embeddings that are not in the training set to the corpus. Defaults to false")
+var freeze = flag.Bool("freeze", false, "Keep the pretrained embeddings as they are while training. Defaults to false, which fine-tunes them")
+var seed = flag.Int64("seed", 0, "Seed for the random numbers used in training, so training runs can be repeated. Defaults to 0, which uses the current time")
+var checkpoint = flag.String("checkpoint", "", "Save checkpoints of the training to this file, so the training can be resumed")
+var checkpointEvery = flag.Int("checkpointEvery", 1, "Save a checkpoint every n epochs. Defaults to 1")
+var resume = flag.String("resume", "", "Resume training from a checkpoint, up to a total of -epoch epochs. The other training flags should be the same as when the checkpoint was saved")
+var solver = flag.String("solver", "adagrad", "Solver to train with. Accepts: {adagrad, adam, rmsprop, sgd}")
+var learnRate = flag.Float64("learnRate", 0.01, "Learn rate of the solver. Defaults to 0.01")
+var decay = flag.String("decay", "constant", "How the learn rate decays. Accepts: {constant, step, cosine}")
+var decaySteps = flag.Int("decaySteps", 1000, "Number of training steps (batches) of each step of decay, or of the cosine decay. Defaults to 1000")
+var decayFactor = flag.Float64("decayFactor", 0.5, "Factor the learn rate is multiplied by at each step of decay, or the factor of the learn rate at the end of the cosine decay. Defaults to 0.5")
+var warmup = flag.Int("warmup", 0, "Number of training steps (batches) the learn rate warms up over. Defaults to 0")
+var clipNorm = flag.Float64("clipNorm", 0, "Clip the gradients to this L2 norm. Defaults to 0, which doesn't clip the gradients")
+var beam = flag.Int("beam", 1, "Beam size used when parsing. Defaults to 1, which parses greedily")
+var report = flag.String("report", "", "Write a detailed evaluation of the trained model on the test set as JSON to this file")
+var punct = flag.Bool("punct", false, "Score punctuation when evaluating on the test set. Defaults to false, which excludes punctuation as in CoNLL-X")
 var format = flag.String("f", "", "Format to output. Default is none. Accepts: {json, dot}")
 
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
@@ -93,6 +117,9 @@ func main() {
 	if toTrain {
 		loadTreebanks()
 		train()
+		if testTB != nil {
+			evaluate()
+		}
 	}
 
 	saveModel()
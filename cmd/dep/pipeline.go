@@ -45,7 +45,7 @@ func pipeline(s string) error {
 	go lx.Run()
 	go pt.Run()
 	go receive(dp.Output, dp.Error, errChan)
-	dp.Run()
+	dp.Run(dep.WithBeam(*beam))
 
 	return <-errChan
 }
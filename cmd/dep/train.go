@@ -4,25 +4,59 @@ import (
 	"log"
 
 	"github.com/chewxy/lingo/dep"
+	"github.com/chewxy/lingo/pos"
 	"github.com/chewxy/lingo/treebank"
 	"gorgonia.org/tensor"
 )
 
 var trainTB []treebank.SentenceTag
 var testTB []treebank.SentenceTag
+var features []dep.FeatureTemplate // parsed from -features in validateFlags()
 
 func train() {
 	conf := dep.DefaultNNConfig
 	conf.Dtype = tensor.Float32
+	conf.TransitionSystem, _ = dep.ParseTransitionSystem(*system) // validated in validateFlags()
+	conf.JointTagging = *joint
+	conf.Features = features
+	conf.Solver, _ = dep.ParseSolverType(*solver) // validated in validateFlags()
+	conf.AdaAlpha = *learnRate
+	conf.Schedule.Decay, _ = dep.ParseDecayType(*decay) // validated in validateFlags()
+	conf.Schedule.Steps = *decaySteps
+	conf.Schedule.Factor = *decayFactor
+	conf.Schedule.Warmup = *warmup
+	conf.ClipNorm = *clipNorm
 	var trainer *dep.Trainer
+	opts := []dep.TrainerConsOpt{dep.WithGeneratedCorpus(trainTB...), dep.WithTrainingSet(trainTB), dep.WithConfig(conf)}
+	if *explore > 0 {
+		opts = append(opts, dep.WithDynamicOracle(dep.ConstantExploration(*explore, 1)))
+	}
+	if *folds > 0 {
+		opts = append(opts, dep.WithJackknifing(*folds, *foldIter, pos.WithStemmer(stemmer{}), pos.WithCluster(clusters)))
+	}
+	if *embeddings != "" {
+		format, _ := dep.ParseEmbeddingFormat(*embeddingFormat) // validated in validateFlags()
+		var popts []dep.PretrainedOpt
+		if *addPretrained {
+			popts = append(popts, dep.AddPretrainedWords())
+		}
+		if *freeze {
+			popts = append(popts, dep.FreezePretrained())
+		}
+		opts = append(opts, dep.WithPretrainedEmbeddings(*embeddings, format, popts...))
+	}
+	if *seed != 0 {
+		opts = append(opts, dep.WithSeed(*seed))
+	}
+	if *checkpoint != "" {
+		opts = append(opts, dep.WithCheckpoints(*checkpoint, *checkpointEvery))
+	}
 
 	if testTB != nil {
 		log.Printf("TRAINING WITH CROSSVALIDATION")
-		trainer = dep.NewTrainer(dep.WithGeneratedCorpus(trainTB...), dep.WithTrainingSet(trainTB), dep.WithCrossValidationSet(testTB), dep.WithConfig(conf))
+		trainer = dep.NewTrainer(append(opts, dep.WithCrossValidationSet(testTB))...)
 		trainer.SaveBest = "TMP.model"
-		if err := trainer.Init(); err != nil {
-			log.Fatalf("Unable to initialize trainer: \n%+v", err)
-		}
+		initTrainer(trainer)
 
 		prog := trainer.Perf()
 		cost := trainer.Cost()
@@ -38,10 +72,8 @@ func train() {
 		}()
 
 	} else {
-		trainer = dep.NewTrainer(dep.WithGeneratedCorpus(trainTB...), dep.WithTrainingSet(trainTB), dep.WithConfig(conf))
-		if err := trainer.Init(); err != nil {
-			log.Fatalf("Unable to initialize trainer: \n%+v", err)
-		}
+		trainer = dep.NewTrainer(opts...)
+		initTrainer(trainer)
 
 		prog := trainer.Cost()
 		go func() {
@@ -51,9 +83,31 @@ func train() {
 		}()
 	}
 
-	if err := trainer.Train(*epoch); err != nil {
+	// -epoch is the total number of epochs, including those trained before the checkpoint
+	epochs := *epoch
+	if *resume != "" {
+		epochs -= trainer.Epoch()
+		if epochs < 0 {
+			epochs = 0
+		}
+		log.Printf("Resuming after %d epochs. Training %d more", trainer.Epoch(), epochs)
+	}
+	if err := trainer.Train(epochs); err != nil {
 		log.Fatal(err)
 	}
 
 	DepModel = trainer.Model
 }
+
+// initTrainer initializes the trainer, or resumes the training from a checkpoint
+func initTrainer(trainer *dep.Trainer) {
+	if *resume != "" {
+		if err := trainer.Resume(*resume); err != nil {
+			log.Fatalf("Unable to resume training: \n%+v", err)
+		}
+		return
+	}
+	if err := trainer.Init(); err != nil {
+		log.Fatalf("Unable to initialize trainer: \n%+v", err)
+	}
+}
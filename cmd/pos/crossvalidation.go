@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"strings"
@@ -42,24 +44,14 @@ func (tr testResult) compare() (int, bool) {
 }
 
 func crossValidate(resultChan chan testResult) {
-	diffLengthCount := 0
-	totalLength := 0
-	correctCount := 0
-	sentences := 0
-
+	var predicted, gold []lingo.AnnotatedSentence
 	var wrongResults []testResult
 
 	for res := range resultChan {
-		sentences++
-		length := len(res.actual)
-		cc, sl := res.compare()
-		if !sl {
-			diffLengthCount++
-		}
-		correctCount += cc
-		totalLength += length
+		predicted = append(predicted, res.tagged)
+		gold = append(gold, res.actual)
 
-		if cc != length && *inspect != "" {
+		if cc, _ := res.compare(); cc != len(res.actual) && *inspect != "" {
 			wrongResults = append(wrongResults, res)
 		}
 	}
@@ -80,7 +72,22 @@ func crossValidate(resultChan chan testResult) {
 		f.Close()
 	}
 
-	fmt.Printf("CrossValidation: %d/%d = %f. Differing Lengths : %d/%d = %f\n", correctCount, totalLength, float64(correctCount)/float64(totalLength), diffLengthCount, sentences, float64(diffLengthCount)/float64(sentences))
+	eval, err := pos.Evaluate(predicted, gold, model)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("CrossValidation:\n%v", eval)
+
+	if *report != "" {
+		bs, err := json.MarshalIndent(eval, "", "\t")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(*report, bs, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Evaluation report written to %v", *report)
+	}
 }
 
 func collect(ch chan lingo.AnnotatedSentence, correct lingo.AnnotatedSentence, outCh chan testResult, wg *sync.WaitGroup) {
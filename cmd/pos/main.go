@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/signal"
@@ -23,10 +24,17 @@ var load = flag.String("load", "", "load a model")
 var clusterFiles = flag.String("cluster", "", "Brown Cluster files. If nothing is passed in, then the brown cluster won't be used")
 var trainFile = flag.String("train", "", "Training on... files that end with '.conllu' will be treated as CONLLU formatted files. Files ending with '.zip' will be treted as EWT files")
 var testFile = flag.String("test", "", "Test on... Files to cross validate the model on. If this is provided, automatic crossvalidation will be done")
+var report = flag.String("report", "", "Write the crossvalidation evaluation as JSON to this file")
 var cv = flag.Bool("cv", false, "Cross validate training model? Defaults to false.")
 var epoch = flag.Int("epoch", 1500, "Training epochs. Defaults to 1500")
 var inspect = flag.String("inpect", "", "Inspect all the wrong outputs to figure out what went wrong in the POSTagging. This is useful for debugging")
 var input = flag.String("input", "", "Input sentence to tag")
+var explain = flag.Bool("explain", false, "Explain the tag of each word of the input sentence, listing the features that contributed most to the chosen tag and the runner-up")
+var templateFile = flag.String("templates", "", "Feature templates file. If nothing is passed in, then the default features will be used")
+var update = flag.String("update", "", "CONLLU file of new sentences to continue training the -load model on")
+var updateReg = flag.Float64("updateReg", 0, "How strongly to regularize towards the loaded model when updating. Between 0 and 1. Defaults to 0")
+
+var seed = flag.Int64("seed", 0, "Seed for shuffling the training sentences, so training runs can be repeated. Defaults to 0, which shuffles the sentences the same way every epoch")
 
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 var memprofile = flag.String("memprofile", "", "write memory profile to this file")
@@ -40,6 +48,15 @@ func receive(sentences chan lingo.AnnotatedSentence, wg *sync.WaitGroup) {
 		for _, a := range sent {
 			fmt.Printf("%#v: %s| %s | %s | %d\n", a, a.POSTag, a.Lemma, a.WordFlag, a.Cluster)
 		}
+
+		if *explain {
+			for i, a := range sent {
+				if a == lingo.RootAnnotation() {
+					continue
+				}
+				fmt.Println(model.Explain(sent, i))
+			}
+		}
 	}
 }
 
@@ -74,13 +91,21 @@ func validateFlags() {
 
 	// warnings
 
+	if *update != "" && *load == "" {
+		log.Fatal("Must load a model to update")
+	}
+
+	if *explain && *input == "" {
+		log.Fatal("Must pass in an input sentence to explain")
+	}
+
 	if *load == "" && *save == "" {
 		log.Println("WARNING: Models that are trained will NOT be saved")
 	}
 }
 
 func loadOrTrain() {
-	var trained *pos.Tagger
+	var opts []pos.ConsOpt
 	if *clusterFiles != "" {
 		f, err := os.Open(*clusterFiles)
 		if err != nil {
@@ -88,11 +113,25 @@ func loadOrTrain() {
 		}
 		clusters = lingo.ReadCluster(f)
 
-		trained = pos.New(pos.WithCluster(clusters), pos.WithStemmer(stemmer{}))
-	} else {
-		trained = pos.New()
+		opts = append(opts, pos.WithCluster(clusters), pos.WithStemmer(stemmer{}))
 	}
 
+	if *templateFile != "" {
+		bs, err := ioutil.ReadFile(*templateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		templates, err := pos.ParseFeatureTemplates(string(bs))
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, pos.WithFeatureTemplates(templates...))
+	}
+	if *seed != 0 {
+		opts = append(opts, pos.WithSeed(*seed))
+	}
+	trained := pos.New(opts...)
+
 	if *load != "" {
 		start := time.Now()
 		var err error
@@ -100,6 +139,10 @@ func loadOrTrain() {
 			log.Fatal(err)
 		}
 		log.Printf("Loading model from %q took %v", *load, time.Since(start))
+
+		if *update != "" {
+			updateModel()
+		}
 		return
 	}
 
@@ -123,8 +166,11 @@ func loadOrTrain() {
 
 	log.Printf("Start training for %d epochs...", *epoch)
 	start := time.Now()
-	trained.Train(sentences, *epoch)
+	if err := trained.Train(sentences, *epoch); err != nil {
+		log.Fatal(err)
+	}
 	log.Printf("End Training. Training took %v minutes", time.Since(start).Minutes())
+	model = trained.Model
 
 	if *save != "" {
 		trained.Save(*save)
@@ -132,6 +178,29 @@ func loadOrTrain() {
 	}
 }
 
+func updateModel() {
+	var opts []pos.ConsOpt
+	if clusters != nil {
+		opts = append(opts, pos.WithCluster(clusters), pos.WithStemmer(stemmer{}))
+	}
+	opts = append(opts, pos.WithModel(model), pos.WithUpdateRegularization(*updateReg))
+	if *seed != 0 {
+		opts = append(opts, pos.WithSeed(*seed))
+	}
+	updated := pos.New(opts...)
+
+	sentences := treebank.LoadUniversal(*update)
+	log.Printf("Start updating for %d epochs...", *epoch)
+	start := time.Now()
+	updated.Update(sentences, *epoch)
+	log.Printf("End Updating. Updating took %v minutes", time.Since(start).Minutes())
+
+	if *save != "" {
+		updated.Save(*save)
+		log.Printf("Model saved as: %v", *save)
+	}
+}
+
 func cleanup(sigChan chan os.Signal, profiling bool) {
 	select {
 	case <-sigChan:
@@ -0,0 +1,118 @@
+package dep
+
+import "github.com/chewxy/lingo"
+
+// arcEagerCanApply checks if a particular transition can be applied in the arc-eager system.
+//
+// In the arc-eager system, arcs are built between the top of the stack (s0) and the front of the buffer (b0):
+//
+//	Left:   b0 → s0, and pops s0. s0 must not be the root, and must not already have a head.
+//	Right:  s0 → b0, and pushes b0. Only one word can be attached to the root.
+//	Reduce: pops s0. s0 must already have a head.
+//	Shift:  pushes b0.
+//
+// When the buffer is empty, words left on the stack without a head can also be reduced. They are attached to the word below them.
+func (c *configuration) arcEagerCanApply(t transition) bool {
+	s0 := c.stackValue(0)
+	b0 := c.bufferValue(0)
+
+	switch t.Move {
+	case Left:
+		if s0 <= 0 || b0 == DOES_NOT_EXIST {
+			return false
+		}
+		return c.Head(int(s0)) < 0 && t.DependencyType != lingo.Root
+	case Right:
+		if s0 < 0 || b0 == DOES_NOT_EXIST {
+			return false
+		}
+		if s0 == 0 {
+			return t.DependencyType == lingo.Root && c.rootChild() < 0
+		}
+		return t.DependencyType != lingo.Root
+	case Reduce:
+		if s0 <= 0 {
+			return false
+		}
+		return c.Head(int(s0)) >= 0 || b0 == DOES_NOT_EXIST
+	}
+	return b0 != DOES_NOT_EXIST
+}
+
+// arcEagerApply applies the transition in the arc-eager system
+func (c *configuration) arcEagerApply(t transition) {
+	logf("Applying %v", t)
+	s0 := int(c.stackValue(0))
+	b0 := int(c.bufferValue(0))
+
+	switch t.Move {
+	case Left:
+		c.AddArc(b0, s0, t.DependencyType)
+		c.removeTopStack()
+	case Right:
+		c.AddArc(s0, b0, t.DependencyType)
+		c.shift()
+	case Reduce:
+		if c.Head(s0) < 0 {
+			// the buffer is empty, and s0 has no head. Attach it to the word below it.
+			s1 := int(c.stackValue(1))
+			switch {
+			case s1 > 0:
+				c.AddArc(s1, s0, lingo.Dep)
+			case c.rootChild() < 0:
+				c.AddArc(0, s0, lingo.Root)
+			default:
+				c.AddArc(c.rootChild(), s0, lingo.Dep)
+			}
+		}
+		c.removeTopStack()
+	default:
+		c.shift()
+	}
+}
+
+// arcEagerOracle gets the gold transition given the state in the arc-eager system.
+// This is the static oracle which only reduces when it has to.
+func (c *configuration) arcEagerOracle(goldParse *lingo.Dependency) (t transition) {
+	s0 := int(c.stackValue(0))
+	b0 := int(c.bufferValue(0))
+
+	if b0 < 0 {
+		t.Move = Reduce
+		return
+	}
+
+	if s0 > 0 && goldParse.Head(s0) == b0 {
+		t.Move = Left
+		t.DependencyType = goldParse.Label(s0)
+		return
+	}
+
+	if s0 >= 0 && goldParse.Head(b0) == s0 {
+		t.Move = Right
+		t.DependencyType = goldParse.Label(b0)
+		return
+	}
+
+	// reduce if a word further down the stack has an arc with b0
+	if s0 > 0 && c.Head(s0) >= 0 {
+		for i := 1; i < c.stackSize(); i++ {
+			k := int(c.stackValue(i))
+			if goldParse.Head(k) == b0 || goldParse.Head(b0) == k {
+				t.Move = Reduce
+				return
+			}
+		}
+	}
+	return // default transition is Shift
+}
+
+// rootChild returns the word attached to the root. If no word is attached to the root, it returns -1
+func (c *configuration) rootChild() int {
+	for i := 1; i < c.WordCount(); i++ {
+		if c.Head(i) == 0 {
+			return i
+		}
+	}
+	return -1
+}
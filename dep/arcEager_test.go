@@ -0,0 +1,114 @@
+package dep
+
+import (
+	"os"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArcEagerOracle(t *testing.T) {
+	for _, st := range allSentences() {
+		s := st.AnnotatedSentence(dummyFix{})
+		d := s.Dependency()
+		if !d.IsProjective() {
+			continue
+		}
+
+		c := newConfiguration(s, true)
+		c.system = ArcEager
+		for count := 0; !c.isTerminal() && count < 1000; count++ {
+			oracle := c.oracle(d)
+			if !c.canApply(oracle) {
+				t.Fatalf("Cannot apply %v to %v", oracle, c)
+			}
+			c.apply(oracle)
+		}
+
+		assert.Equal(t, d.Heads(), c.Heads())
+		assert.Equal(t, d.Labels(), c.Labels())
+	}
+}
+
+func TestArcEagerCanApply(t *testing.T) {
+	assert := assert.New(t)
+	s := simpleSentence()[0].AnnotatedSentence(dummyFix{})
+	c := newConfiguration(s, true)
+	c.system = ArcEager
+
+	// the root cannot be a dependent, nor be reduced
+	assert.False(c.canApply(transition{Left, lingo.NSubj, lingo.X}))
+	assert.False(c.canApply(transition{Reduce, lingo.NoDepType, lingo.X}))
+	assert.False(c.canApply(transition{Right, lingo.NSubj, lingo.X}))
+	assert.True(c.canApply(transition{Right, lingo.Root, lingo.X}))
+	assert.True(c.canApply(transition{Shift, lingo.NoDepType, lingo.X}))
+
+	c.apply(transition{Right, lingo.Root, lingo.X})
+	c.apply(transition{Reduce, lingo.NoDepType, lingo.X})
+
+	// only one word may be attached to the root
+	assert.False(c.canApply(transition{Right, lingo.Root, lingo.X}))
+
+	// words without a head can only be reduced once the buffer is empty
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	assert.False(c.canApply(transition{Reduce, lingo.NoDepType, lingo.X}))
+	for c.bufferSize() > 0 {
+		c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	}
+	for !c.isTerminal() {
+		if !c.canApply(transition{Reduce, lingo.NoDepType, lingo.X}) {
+			t.Fatalf("Expected to be able to reduce %v", c)
+		}
+		c.apply(transition{Reduce, lingo.NoDepType, lingo.X})
+	}
+
+	for i := 1; i < c.WordCount(); i++ {
+		if c.Head(i) < 0 {
+			t.Errorf("Expected word %d to have a head", i)
+		}
+	}
+	assert.True(c.HasSingleRoot())
+}
+
+func TestTrainer_ArcEager(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.TransitionSystem = ArcEager
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(t, len(ArcEager.transitions()), trainer.nn.w2.Shape()[0])
+
+	if err := trainer.Train(2); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	p := New(trainer.Model)
+	for _, st := range sts {
+		d, err := p.predict(st.AnnotatedSentence(dummyFix{}), parseOpts{})
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		for i := 1; i < d.WordCount(); i++ {
+			if d.Head(i) < 0 {
+				t.Errorf("Expected word %d of %q to have a head", i, d.ValueString())
+			}
+		}
+	}
+
+	if err := trainer.Save("arceager.dat"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove("arceager.dat")
+
+	m, err := Load("arceager.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ArcEager, m.nn.TransitionSystem)
+	assert.Equal(t, ArcEager.transitions(), m.ts)
+}
@@ -4,8 +4,8 @@ import "github.com/chewxy/lingo"
 
 // var SingleRoot bool = true // make this part of a build process
 
-// canApply checks if a particular transition can be applied
-func (c *configuration) canApply(t transition) bool {
+// arcStandardCanApply checks if a particular transition can be applied
+func (c *configuration) arcStandardCanApply(t transition) bool {
 
 	var h head
 	if t.Move == Left || t.Move == Right {
@@ -41,8 +41,8 @@ func (c *configuration) canApply(t transition) bool {
 
 }
 
-// apply applies the transition
-func (c *configuration) apply(t transition) {
+// arcStandardApply applies the transition
+func (c *configuration) arcStandardApply(t transition) {
 	logf("Applying %v", t)
 	w1 := int(c.stackValue(1))
 	w2 := int(c.stackValue(0))
@@ -58,8 +58,8 @@ func (c *configuration) apply(t transition) {
 	}
 }
 
-// oracle gets the gold transition given the state
-func (c *configuration) oracle(goldParse *lingo.Dependency) (t transition) {
+// arcStandardOracle gets the gold transition given the state
+func (c *configuration) arcStandardOracle(goldParse *lingo.Dependency) (t transition) {
 	w1 := int(c.stackValue(1))
 	w2 := int(c.stackValue(0))
 
@@ -27,11 +27,11 @@ func TestCanApply(t *testing.T) {
 
 	logf("Start config: \n%v", c)
 
-	rootLeft := c.canApply(transition{Left, lingo.Root})
-	rootRight := c.canApply(transition{Right, lingo.Root})
-	NSubjLeft := c.canApply(transition{Left, lingo.NSubj})
-	NSubjRight := c.canApply(transition{Right, lingo.NSubj})
-	ShiftDep := c.canApply(transition{Shift, lingo.NoDepType})
+	rootLeft := c.canApply(transition{Left, lingo.Root, lingo.X})
+	rootRight := c.canApply(transition{Right, lingo.Root, lingo.X})
+	NSubjLeft := c.canApply(transition{Left, lingo.NSubj, lingo.X})
+	NSubjRight := c.canApply(transition{Right, lingo.NSubj, lingo.X})
+	ShiftDep := c.canApply(transition{Shift, lingo.NoDepType, lingo.X})
 
 	assert.Equal(false, rootLeft, "rootLeft should be false")
 	assert.Equal(false, rootRight, "rootRight should be false")
@@ -47,11 +47,11 @@ func TestCanApply(t *testing.T) {
 	c.shift()
 	logf("%v", c)
 
-	rootLeft = c.canApply(transition{Left, lingo.Root})
-	rootRight = c.canApply(transition{Right, lingo.Root})
-	NSubjLeft = c.canApply(transition{Left, lingo.NSubj})
-	NSubjRight = c.canApply(transition{Right, lingo.NSubj})
-	ShiftDep = c.canApply(transition{Shift, lingo.NoDepType})
+	rootLeft = c.canApply(transition{Left, lingo.Root, lingo.X})
+	rootRight = c.canApply(transition{Right, lingo.Root, lingo.X})
+	NSubjLeft = c.canApply(transition{Left, lingo.NSubj, lingo.X})
+	NSubjRight = c.canApply(transition{Right, lingo.NSubj, lingo.X})
+	ShiftDep = c.canApply(transition{Shift, lingo.NoDepType, lingo.X})
 
 	assert.Equal(true, rootLeft, "rootLeft should be true")
 	assert.Equal(true, rootRight, "rootRight should be true")
@@ -73,7 +73,7 @@ func TestOracle(t *testing.T) {
 	for count := 0; !c.isTerminal() && count < 100; count++ {
 		oracle := c.oracle(d)
 
-		if !c.canApply(oracle) && (oracle != transition{Right, lingo.Root}) {
+		if !c.canApply(oracle) && (oracle != transition{Right, lingo.Root, lingo.X}) {
 			t.Errorf("Cannot apply %v", oracle)
 			break
 		}
@@ -0,0 +1,145 @@
+package dep
+
+import "github.com/chewxy/lingo"
+
+// arcSwapCanApply checks if a particular transition can be applied in the arc-standard system with swaps.
+//
+// Left, Right and Shift are the same as in the arc-standard system.
+// Swap moves the second word on the stack (s1) back to the front of the buffer, reordering the words so that
+// non-projective trees can be built. Only words that are in their original order can be swapped, so swaps don't loop.
+func (c *configuration) arcSwapCanApply(t transition) bool {
+	if t.Move != Swap {
+		return c.arcStandardCanApply(t)
+	}
+
+	s0 := c.stackValue(0)
+	s1 := c.stackValue(1)
+	return s1 > 0 && s1 < s0
+}
+
+// arcSwapApply applies the transition in the arc-standard system with swaps
+func (c *configuration) arcSwapApply(t transition) {
+	if t.Move != Swap {
+		c.arcStandardApply(t)
+		return
+	}
+
+	logf("Applying %v", t)
+	s1 := c.stackValue(1)
+	c.removeSecondTopStack()
+
+	// the buffer pointer is always past s1 and s0, so the slot before it is free to be reused
+	c.bp--
+	c.buffer[c.bp] = s1
+}
+
+// arcSwapOracle gets the gold transition given the state in the arc-standard system with swaps.
+//
+// This is the lazy swap oracle described by Nivre, Kuhlmann and Hall in "An Improved Oracle for Dependency Parsing with Online Reordering" (2009).
+// Words are swapped when they are out of projective order, but only once the maximal projective component of the top of the stack is complete.
+func (c *configuration) arcSwapOracle(goldParse *lingo.Dependency) (t transition) {
+	if c.swapOrder == nil {
+		c.swapOrder = projectiveOrder(goldParse)
+		c.mpc = maximalProjectiveComponents(goldParse)
+	}
+
+	s0 := int(c.stackValue(0))
+	s1 := int(c.stackValue(1))
+	b0 := int(c.bufferValue(0))
+
+	if s1 > 0 && goldParse.Head(s1) == s0 && !c.hasOtherChildren(s1, goldParse) {
+		t.Move = Left
+		t.DependencyType = goldParse.Label(s1)
+		return
+	}
+
+	if s1 >= 0 && goldParse.Head(s0) == s1 && !c.hasOtherChildren(s0, goldParse) {
+		t.Move = Right
+		t.DependencyType = goldParse.Label(s0)
+		return
+	}
+
+	if s1 > 0 && c.swapOrder[s0] < c.swapOrder[s1] && (b0 < 0 || c.mpc[s0] != c.mpc[b0]) {
+		t.Move = Swap
+		return
+	}
+	return // default transition is Shift
+}
+
+// projectiveOrder returns the position of each word in the projective order of the tree - the order in which
+// the words are visited in an inorder traversal of the tree. A tree is projective if the projective order is the same as the linear order.
+func projectiveOrder(d *lingo.Dependency) []int {
+	order := make([]int, d.WordCount())
+	var counter int
+	var visit func(w int)
+	visit = func(w int) {
+		for i := 1; i < w; i++ {
+			if d.Head(i) == w {
+				visit(i)
+			}
+		}
+		order[w] = counter
+		counter++
+		for i := w + 1; i < d.WordCount(); i++ {
+			if d.Head(i) == w {
+				visit(i)
+			}
+		}
+	}
+	visit(0)
+	return order
+}
+
+// maximalProjectiveComponents finds the maximal projective components of the tree - the subtrees that can be built without any swaps.
+// It returns the ID of the component of each word.
+//
+// The components are found by parsing the sentence in the arc-standard system, attaching words only when they're complete.
+func maximalProjectiveComponents(d *lingo.Dependency) []int {
+	heads := make([]int, d.WordCount())
+	for i := range heads {
+		heads[i] = -1
+	}
+
+	complete := func(w int) bool {
+		for i := 1; i < d.WordCount(); i++ {
+			if d.Head(i) == w && heads[i] != w {
+				return false
+			}
+		}
+		return true
+	}
+
+	stack := []int{0}
+	for b := 1; b < d.WordCount() || len(stack) > 1; {
+		if len(stack) >= 2 {
+			s0 := stack[len(stack)-1]
+			s1 := stack[len(stack)-2]
+			if s1 > 0 && d.Head(s1) == s0 && complete(s1) {
+				heads[s1] = s0
+				stack = append(stack[:len(stack)-2], s0)
+				continue
+			}
+			if d.Head(s0) == s1 && complete(s0) {
+				heads[s0] = s1
+				stack = stack[:len(stack)-1]
+				continue
+			}
+		}
+		if b >= d.WordCount() {
+			break
+		}
+		stack = append(stack, b)
+		b++
+	}
+
+	// the component of each word is the top of its partial tree
+	mpc := make([]int, d.WordCount())
+	for i := range mpc {
+		w := i
+		for heads[w] >= 0 {
+			w = heads[w]
+		}
+		mpc[i] = w
+	}
+	return mpc
+}
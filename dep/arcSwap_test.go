@@ -0,0 +1,86 @@
+package dep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo/treebank"
+	"github.com/stretchr/testify/assert"
+)
+
+const nonprojective = `1	A	a	DET	DT	_	2	det	_	_
+2	hearing	hearing	NOUN	NN	_	4	nsubj	_	_
+3	is	be	AUX	VBZ	_	4	aux	_	_
+4	scheduled	schedule	VERB	VBN	_	0	root	_	_
+5	on	on	ADP	IN	_	7	case	_	_
+6	the	the	DET	DT	_	7	det	_	_
+7	issue	issue	NOUN	NN	_	2	nmod	_	_
+8	today	today	NOUN	NN	_	4	nmod	_	_
+9	.	.	PUNCT	.	_	4	punct	_	_
+
+`
+
+func TestArcSwapOracle(t *testing.T) {
+	sts := treebank.ReadConllu(strings.NewReader(nonprojective))
+	sts = append(sts, allSentences()...)
+
+	for i, st := range sts {
+		s := st.AnnotatedSentence(dummyFix{})
+		d := s.Dependency()
+		if i == 0 && d.IsProjective() {
+			t.Fatal("Expected the first sentence to be non-projective")
+		}
+
+		c := newConfiguration(s, true)
+		c.system = ArcSwap
+		var swaps int
+		for count := 0; !c.isTerminal() && count < 1000; count++ {
+			oracle := c.oracle(d)
+			if oracle.Move == Swap {
+				swaps++
+			}
+			if !c.canApply(oracle) {
+				t.Fatalf("Cannot apply %v to %v", oracle, c)
+			}
+			c.apply(oracle)
+		}
+
+		assert.Equal(t, d.Heads(), c.Heads())
+		assert.Equal(t, d.Labels(), c.Labels())
+		if d.IsProjective() != (swaps == 0) {
+			t.Errorf("Sentence %d: %d swaps. Projective: %t", i, swaps, d.IsProjective())
+		}
+	}
+}
+
+func TestProjectiveOrder(t *testing.T) {
+	sts := treebank.ReadConllu(strings.NewReader(nonprojective))
+	d := sts[0].AnnotatedSentence(dummyFix{}).Dependency()
+
+	// A hearing on the issue is scheduled today .
+	assert.Equal(t, []int{0, 1, 2, 6, 7, 3, 4, 5, 8, 9}, projectiveOrder(d))
+
+	// "A hearing" and "on the issue" are built without swaps, but they can only be joined after "is" has been swapped past them
+	mpc := maximalProjectiveComponents(d)
+	assert.Equal(t, mpc[1], mpc[2])
+	assert.Equal(t, mpc[5], mpc[7])
+	assert.NotEqual(t, mpc[2], mpc[7])
+}
+
+func TestMakeOneExample_NonProjective(t *testing.T) {
+	st := treebank.ReadConllu(strings.NewReader(nonprojective))[0]
+
+	if _, err := makeOneExample(0, st, ArcStandard, false, defaultFeatures, KnownWords, ArcStandard.transitions(), dummyFix{}); err == nil {
+		t.Error("Expected a NonProjectiveError for the arc-standard system")
+	} else if _, ok := err.(NonProjectiveError); !ok {
+		t.Errorf("Expected a NonProjectiveError. Got %v", err)
+	}
+
+	exs, err := makeOneExample(0, st, ArcSwap, false, defaultFeatures, KnownWords, ArcSwap.transitions(), dummyFix{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exs) == 0 {
+		t.Error("Expected examples from a non-projective tree")
+	}
+}
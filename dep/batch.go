@@ -0,0 +1,94 @@
+package dep
+
+import (
+	"math"
+
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// ParseBatch parses many sentences at once. The sentences are parsed greedily, in lockstep: at every step the hidden layers of all the
+// sentences that haven't been fully parsed are stacked into a matrix, and the scores of the transitions are computed with one matrix multiplication.
+//
+// The parses are the same as the ones from Parse, but parsing a corpus this way is much faster. Like Parse, the sentences that couldn't be
+// parsed to the end are repaired. The TarpitError of the first of them is returned along with the parses.
+func (d *Parser) ParseBatch(sentences []lingo.AnnotatedSentence) ([]*lingo.Dependency, error) {
+	pc, err := d.nn.getPrecomputed()
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]*configuration, len(sentences))
+	for i, s := range sentences {
+		cs[i] = newConfiguration(s, hasHeads(s))
+		cs[i].setSystem(d.nn.TransitionSystem, d.nn.JointTagging)
+	}
+
+	active := make([]int, 0, len(cs)) // the indices of the configurations that are still being parsed
+	hidden := make([]float64, len(cs)*pc.hidden)
+	steps := make([]int, len(cs))
+	stuck := make([]bool, len(cs))
+	for count := 0; ; count++ {
+		active = active[:0]
+		for i, c := range cs {
+			if !c.isTerminal() && !stuck[i] && steps[i] < c.budget() {
+				active = append(active, i)
+			}
+		}
+		if len(active) == 0 {
+			break
+		}
+
+		for row, i := range active {
+			features := d.nn.fs.extract(cs[i], d.corpus)
+			pc.hiddenLayer(features, hidden[row*pc.hidden:(row+1)*pc.hidden])
+		}
+
+		h := tensor.New(tensor.WithShape(len(active), pc.hidden), tensor.WithBacking(hidden[:len(active)*pc.hidden]))
+		s, err := tensor.MatMul(h, pc.w2T)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to compute the scores of step %d", count)
+		}
+		scores := s.Data().([]float64)
+
+		for row, i := range active {
+			c := cs[i]
+			j := bestApplicableIndex(c, d.ts, scores[row*pc.trns:(row+1)*pc.trns])
+			if j < 0 {
+				stuck[i] = true
+				continue
+			}
+			c.apply(d.ts[j])
+			steps[i]++
+		}
+	}
+
+	var tarpit error
+	retVal := make([]*lingo.Dependency, len(cs))
+	for i, c := range cs {
+		if !c.isTerminal() {
+			err := c.tarpit(steps[i])
+			if tarpit == nil {
+				tarpit = err
+			}
+		}
+		d.postprocess(c.Dependency, nil)
+		retVal[i] = c.Dependency
+	}
+	return retVal, tarpit
+}
+
+// bestApplicableIndex returns the index of the highest scoring transition that can be applied to the configuration.
+// If none of the transitions can be applied, -1 is returned.
+func bestApplicableIndex(c *configuration, ts []transition, scores []float64) int {
+	best := -1
+	maxScore := math.Inf(-1)
+	for i, kt := range ts {
+		if scores[i] > maxScore && c.canApply(kt) {
+			maxScore = scores[i]
+			best = i
+		}
+	}
+	return best
+}
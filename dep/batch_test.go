@@ -0,0 +1,83 @@
+package dep
+
+import (
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ParseBatch(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := trainer.Train(1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	p := New(trainer.Model)
+	var sentences []lingo.AnnotatedSentence
+	for _, st := range sts {
+		sentences = append(sentences, st.AnnotatedSentence(dummyFix{}))
+	}
+
+	ds, err := p.ParseBatch(sentences)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(ds) != len(sentences) {
+		t.Fatalf("Expected %d parses. Got %d", len(sentences), len(ds))
+	}
+
+	for i, s := range sentences {
+		d, err := p.Parse(s)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		assert.Equal(t, d.Heads(), ds[i].Heads(), "Sentence %d", i)
+		assert.Equal(t, d.Labels(), ds[i].Labels(), "Sentence %d", i)
+	}
+
+	if ds, err = p.ParseBatch(nil); err != nil || len(ds) != 0 {
+		t.Errorf("Expected an empty batch to parse to nothing. Got %v, %v", ds, err)
+	}
+}
+
+func BenchmarkParseBatch(b *testing.B) {
+	trainer := precomputeTestTrainer(b, DefaultNNConfig)
+	p := New(trainer.Model)
+	var sentences []lingo.AnnotatedSentence
+	for _, st := range allSentences() {
+		sentences = append(sentences, st.AnnotatedSentence(dummyFix{}))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.ParseBatch(sentences); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseSequential(b *testing.B) {
+	trainer := precomputeTestTrainer(b, DefaultNNConfig)
+	p := New(trainer.Model)
+	var sentences []lingo.AnnotatedSentence
+	for _, st := range allSentences() {
+		sentences = append(sentences, st.AnnotatedSentence(dummyFix{}))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, s := range sentences {
+			if _, err := p.Parse(s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package dep
+
+import (
+	"math"
+	"sort"
+
+	"github.com/chewxy/lingo"
+)
+
+// ParseOpt is an option for a single call to Parse.
+type ParseOpt func(*parseOpts)
+
+type parseOpts struct {
+	beam        int
+	constraints *Constraints
+}
+
+// WithBeam sets the number of configurations kept by the beam search decoder.
+// A beam size of 1 or less parses greedily.
+func WithBeam(k int) ParseOpt {
+	f := func(o *parseOpts) {
+		o.beam = k
+	}
+	return f
+}
+
+// beamItem is a configuration on the beam, along with the scores of the transitions that led to it
+type beamItem struct {
+	c *configuration
+	s *arcScores
+}
+
+// candidate is a transition that may be applied to a configuration on the beam
+type candidate struct {
+	parent  int
+	t       transition
+	score   float64 // the cumulative log-probability of the configuration after the transition is applied
+	logProb float64 // the log-probability of the transition
+	alt     float64 // the log-probability of the best alternative to the transition
+}
+
+// beamSearch parses the sentence, keeping the k best configurations by cumulative log-probability at every step.
+// It returns the different parses of the completed derivations, the best first. Parses that don't satisfy the constraints are dropped.
+// If none of the parses could be completed, the best of them is repaired, and returned with a TarpitError.
+func (d *Parser) beamSearch(sentence lingo.AnnotatedSentence, o parseOpts) ([]*ScoredParse, error) {
+	c, err := d.start(sentence, o)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := d.nn.newScratch()
+	if err != nil {
+		return nil, err
+	}
+
+	score := func(c *configuration) []float64 {
+		features := d.nn.fs.extract(c, d.corpus)
+		sc.pred(features)
+		logSoftmax(sc.scores)
+		return sc.scores
+	}
+	return d.search(c, o.beam, score)
+}
+
+// search is the beam search proper. score returns the log-probabilities of the transitions of the parser for a configuration.
+//
+// The configurations on the beam have all had the same number of transitions applied, so they are compared by their cumulative log-probability.
+// Completed derivations are taken off the beam, and the search carries on with fewer configurations until k derivations are completed.
+// Derivations can differ in length (in ArcSwap, every Swap takes two more transitions), so the completed derivations are ranked by the mean log-probability of their transitions.
+func (d *Parser) search(c *configuration, k int, score func(*configuration) []float64) ([]*ScoredParse, error) {
+	beam := []beamItem{{c: c, s: newArcScores(c)}}
+	var finished []beamItem
+	var rejected *configuration // the first completed configuration that doesn't satisfy the constraints
+	var reason string
+	complete := func(item beamItem) {
+		if c.cons != nil {
+			if r := c.cons.check(item.c.Dependency); r != "" {
+				if rejected == nil {
+					rejected, reason = item.c, r
+				}
+				return
+			}
+		}
+		finished = append(finished, item)
+	}
+	if c.isTerminal() {
+		complete(beam[0])
+		beam = nil
+	}
+
+	candidates := make([]candidate, 0, k*len(d.ts))
+	applicable := make([]int, 0, len(d.ts))
+	budget := c.budget()
+	var count int
+	for ; count < budget && len(beam) > 0 && len(finished) < k; count++ {
+		candidates = candidates[:0]
+		for i, item := range beam {
+			scores := score(item.c)
+
+			// the two most probable applicable transitions, for the margins
+			applicable = applicable[:0]
+			first, second := math.Inf(-1), math.Inf(-1)
+			for j, t := range d.ts {
+				if !item.c.canApply(t) {
+					continue
+				}
+				applicable = append(applicable, j)
+				switch {
+				case scores[j] > first:
+					first, second = scores[j], first
+				case scores[j] > second:
+					second = scores[j]
+				}
+			}
+
+			for _, j := range applicable {
+				alt := first
+				if scores[j] == first {
+					alt = second
+				}
+				candidates = append(candidates, candidate{parent: i, t: d.ts[j], score: item.s.score + scores[j], logProb: scores[j], alt: alt})
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+		if width := k - len(finished); len(candidates) > width {
+			candidates = candidates[:width]
+		}
+
+		next := make([]beamItem, 0, len(candidates))
+		for _, cand := range candidates {
+			parent := beam[cand.parent]
+			item := beamItem{c: parent.c.clone(), s: parent.s.clone()}
+			item.s.apply(item.c, cand.t, cand.logProb, cand.alt)
+			if item.c.isTerminal() {
+				complete(item)
+				continue
+			}
+			next = append(next, item)
+		}
+		beam = next
+	}
+
+	// Different transition sequences may lead to the same parse, so only the first of those is kept.
+	sort.SliceStable(finished, func(i, j int) bool { return finished[i].s.mean() > finished[j].s.mean() })
+	var retVal []*ScoredParse
+	for _, item := range finished {
+		if p := item.s.finish(item.c, d); !seen(retVal, p) {
+			retVal = append(retVal, p)
+		}
+	}
+	if len(retVal) == 0 && c.cons != nil {
+		if rejected == nil {
+			rejected, reason = beam[0].c, "the parser is stuck"
+		}
+		return nil, ConstraintError{rejected.Dependency, reason}
+	}
+	if len(retVal) == 0 {
+		err := beam[0].c.tarpit(count)
+		return []*ScoredParse{beam[0].s.finish(beam[0].c, d)}, err
+	}
+	return retVal, nil
+}
+
+// seen checks if any of the parses has the same arcs as the parse p
+func seen(parses []*ScoredParse, p *ScoredParse) bool {
+	for _, q := range parses {
+		same := true
+		for i := 1; i < p.WordCount() && same; i++ {
+			same = q.Head(i) == p.Head(i) && q.Label(i) == p.Label(i)
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+// logSoftmax converts the scores into log-probabilities in place
+func logSoftmax(scores []float64) {
+	max := math.Inf(-1)
+	for _, s := range scores {
+		if s > max {
+			max = s
+		}
+	}
+
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - max)
+	}
+	logZ := max + math.Log(sum)
+
+	for i := range scores {
+		scores[i] -= logZ
+	}
+}
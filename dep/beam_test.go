@@ -0,0 +1,222 @@
+package dep
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfiguration_clone(t *testing.T) {
+	s := simpleSentence()[0].AnnotatedSentence(dummyFix{})
+	c := newConfiguration(s, true)
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	c.apply(transition{Left, lingo.NSubj, lingo.X})
+
+	c2 := c.clone()
+	assert.Equal(t, c.Heads(), c2.Heads())
+	assert.Equal(t, c.Labels(), c2.Labels())
+	assert.Equal(t, c.String(), c2.String())
+
+	// transitioning the clone leaves the original alone
+	heads := c.Heads()
+	c2.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	c2.apply(transition{Left, lingo.Dep, lingo.X})
+	assert.Equal(t, heads, c.Heads())
+	assert.NotEqual(t, c.String(), c2.String())
+}
+
+func TestLogSoftmax(t *testing.T) {
+	scores := []float64{1, 2, 3, 1000}
+	logSoftmax(scores)
+
+	var sum float64
+	for _, s := range scores {
+		if s > 0 {
+			t.Errorf("Expected log-probabilities to be at most 0. Got %v", s)
+		}
+		sum += math.Exp(s)
+	}
+	assert.InDelta(t, 1, sum, 1e-9)
+}
+
+func TestParser_Parse(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.Dropout = 0 // dropout makes the predictions nondeterministic
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := trainer.Train(1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	p := New(trainer.Model)
+	for _, st := range sts {
+		s := st.AnnotatedSentence(dummyFix{})
+		greedy, err := p.predict(s, parseOpts{})
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		d, err := p.Parse(s, WithBeam(1))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		assert.Equal(t, greedy.Heads(), d.Heads())
+
+		if d, err = p.Parse(s, WithBeam(4)); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		for i := 1; i < d.WordCount(); i++ {
+			if d.Head(i) < 0 {
+				t.Errorf("Expected word %d of %q to have a head", i, d.ValueString())
+			}
+		}
+	}
+}
+
+func TestParser_ParseScored(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.Dropout = 0
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := trainer.Train(1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	p := New(trainer.Model)
+	for _, st := range sts {
+		s := st.AnnotatedSentence(dummyFix{})
+		greedy, err := p.ParseScored(s)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if greedy.Score > 0 {
+			t.Errorf("Expected the score to be a log-probability. Got %v", greedy.Score)
+		}
+		assert.Equal(t, 0.0, greedy.Confidence[0], "the root isn't attached to anything")
+		for i := 1; i < greedy.WordCount(); i++ {
+			if greedy.Confidence[i] < 0 || greedy.Confidence[i] > 1 {
+				t.Errorf("Expected the confidence of word %d to be a probability. Got %v", i, greedy.Confidence[i])
+			}
+			if greedy.Margin[i] < 0 || greedy.Margin[i] > greedy.Confidence[i] {
+				t.Errorf("Expected the margin of word %d of a greedy parse to be between 0 and its confidence. Got %v", i, greedy.Margin[i])
+			}
+		}
+		assert.Equal(t, greedy.WordCount()-1, len(greedy.Arcs(0)))
+		assert.Empty(t, greedy.Arcs(1.1))
+		for _, a := range greedy.Arcs(0.5) {
+			assert.True(t, a.Confidence >= 0.5)
+			assert.Equal(t, greedy.Head(a.Dependent), a.Head)
+			assert.Equal(t, greedy.Label(a.Dependent), a.Label)
+		}
+
+		kbest, err := p.KBest(s, 4)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if len(kbest) < 1 || len(kbest) > 4 {
+			t.Fatalf("Expected between 1 and 4 parses. Got %d", len(kbest))
+		}
+		best, err := p.ParseScored(s, WithBeam(4))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		assert.Equal(t, best.Heads(), kbest[0].Heads())
+		assert.Equal(t, best.Score, kbest[0].Score)
+		for i := 1; i < len(kbest); i++ {
+			if kbest[i].Score > kbest[i-1].Score {
+				t.Errorf("Expected the parses to be sorted by score. Got %v after %v", kbest[i].Score, kbest[i-1].Score)
+			}
+			if seen(kbest[:i], kbest[i]) {
+				t.Errorf("Expected parse %d to be different from the ones before it", i)
+			}
+		}
+	}
+}
+
+// TestParser_search checks that derivations of different lengths are compared fairly. The swapping derivation takes two more
+// transitions than the other one, so it is less probable overall, but its transitions are more probable on average.
+func TestParser_search(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := ArcSwap.transitions()
+	score := func(c *configuration) []float64 {
+		s0, s1 := c.stackValue(0), c.stackValue(1)
+		scores := make([]float64, len(ts))
+		for i, t := range ts {
+			switch {
+			case t.Move == Shift:
+				scores[i] = 0
+			case t.Move == Swap:
+				scores[i] = -2
+			case s1 == 0 && t.DependencyType != lingo.Root, s1 > 0 && t.DependencyType != lingo.Dep:
+				scores[i] = -100
+			case s1 == 0 && c.bufferSize() > 0:
+				scores[i] = -10 // attaching to the root before the sentence is read
+			case s1 == 0:
+				scores[i] = -1
+			case s1 < s0:
+				scores[i] = -3 // the words are in their original order
+			default:
+				scores[i] = -1.5 // the words have been swapped
+			}
+		}
+		return scores
+	}
+
+	d := &Parser{Model: &Model{ts: ts}}
+	st := simpleSentence()[0]
+	s := lingo.AnnotatedSentence{lingo.RootAnnotation()}
+	for j, lex := range st.Sentence[:2] {
+		s = append(s, lingo.AnnotationFromLexTag(lex, st.Tags[j], dummyFix{}))
+	}
+	c := newConfiguration(s, false)
+	c.setSystem(ArcSwap, false)
+
+	ps, err := d.search(c, 2, score)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(ps, 2) {
+		return
+	}
+	assert.Equal(-4.5, ps[0].Score, "Shift Shift Swap Shift Left Right")
+	assert.Equal(1, ps[0].Head(2))
+	assert.Equal(-4.0, ps[1].Score, "Shift Shift Left Right")
+	assert.Equal(2, ps[1].Head(1))
+}
+
+func TestArcScores_apply(t *testing.T) {
+	for _, system := range []TransitionSystem{ArcStandard, ArcEager, ArcSwap} {
+		for _, st := range allSentences() {
+			s := st.AnnotatedSentence(dummyFix{})
+			gold := s.Dependency()
+			if system.projective() && !gold.IsProjective() {
+				continue
+			}
+			c := newConfiguration(s, true)
+			c.setSystem(system, false)
+
+			sc := newArcScores(c)
+			for count := 0; !c.isTerminal() && count < 1000; count++ {
+				sc.apply(c, c.oracle(gold), -0.5, -1)
+			}
+			for i := 1; i < c.WordCount(); i++ {
+				if sc.confidence[i] != math.Exp(-0.5) || sc.margin[i] != math.Exp(-0.5)-math.Exp(-1) {
+					t.Errorf("%v: Expected the arc of word %d of %q to be scored. Got a confidence of %v and a margin of %v", system, i, c.ValueString(), sc.confidence[i], sc.margin[i])
+				}
+			}
+		}
+	}
+}
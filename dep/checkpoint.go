@@ -0,0 +1,212 @@
+package dep
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/chewxy/lingo/treebank"
+	"github.com/pkg/errors"
+)
+
+// countingSource is a rand.Source that counts the numbers drawn from it. The state of the source is the seed and the count,
+// so it can be saved in a checkpoint and restored by drawing as many numbers again.
+type countingSource struct {
+	src   rand.Source
+	seed  int64
+	draws uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed), seed: seed}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.draws = 0
+}
+
+// restore sets the state of the source to the state it was in after the given number of draws from the given seed
+func (s *countingSource) restore(seed int64, draws uint64) {
+	s.Seed(seed)
+	for s.draws < draws {
+		s.Int63()
+	}
+}
+
+// checkpoint is the state of a Trainer that isn't part of the Model.
+type checkpoint struct {
+	Epoch int
+	Best  Performance
+
+	// random number generator
+	Seed  int64
+	Draws uint64
+
+	Solver []byte
+	Frozen map[int][]float64
+
+	// the training set is kept, as it may have been jackknifed
+	TrainingSet []treebank.SentenceTag
+
+	// the order the examples will be trained on, as the positions of the examples made from the training set.
+	// The examples themselves are made again when resuming, as they are far larger than the training set.
+	Order []int
+}
+
+// WithCheckpoints sets up a *Trainer to save a checkpoint of the training to the given file every so many epochs.
+// The file is overwritten each time. Use Resume to continue training from a checkpoint.
+func WithCheckpoints(filename string, every int) TrainerConsOpt {
+	f := func(t *Trainer) {
+		t.checkpoint = filename
+		t.checkpointEvery = every
+	}
+	return f
+}
+
+// SaveCheckpoint saves the full state of the training to a file: the model, the state of the solver, the number of epochs trained,
+// the state of the random number generator and the order of the training examples.
+//
+// The checkpoint is written to a temporary file first, so a crash while saving doesn't destroy the previous checkpoint.
+func (t *Trainer) SaveCheckpoint(filename string) error {
+	tmp := filename + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to create checkpoint %q", tmp)
+	}
+	if err = t.SaveCheckpointWriter(f); err != nil {
+		os.Remove(tmp)
+		return errors.Wrapf(err, "Unable to save checkpoint %q", tmp)
+	}
+	return os.Rename(tmp, filename)
+}
+
+// SaveCheckpointWriter saves the full state of the training to a io.WriteCloser, and closes it. See SaveCheckpoint.
+func (t *Trainer) SaveCheckpointWriter(f io.WriteCloser) error {
+	w := bufio.NewWriter(f)
+	if err := t.encodeCheckpoint(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "Unable to flush the checkpoint")
+	}
+	return errors.Wrap(f.Close(), "Unable to close the checkpoint")
+}
+
+func (t *Trainer) encodeCheckpoint(w io.Writer) error {
+	if t.nn == nil || !t.nn.initialized() {
+		return errors.Errorf("Cannot checkpoint a Trainer that hasn't been initialized")
+	}
+
+	encoder := gob.NewEncoder(w)
+
+	if err := encoder.Encode(t.corpus); err != nil {
+		return err
+	}
+	if err := encoder.Encode(t.nn); err != nil {
+		return err
+	}
+
+	state, err := t.nn.solver.GobEncode()
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode the state of the solver")
+	}
+
+	cp := checkpoint{
+		Epoch:       t.epoch,
+		Best:        t.best,
+		Seed:        t.src.seed,
+		Draws:       t.src.draws,
+		Solver:      state,
+		Frozen:      t.nn.frozen,
+		TrainingSet: t.trainingSet,
+	}
+	if t.exploration == nil {
+		cp.Order = make([]int, len(t.examples))
+		for i, ex := range t.examples {
+			cp.Order[i] = ex.id
+		}
+	}
+	return encoder.Encode(cp)
+}
+
+// Resume initializes the Trainer from a checkpoint saved by SaveCheckpoint, instead of Init. Training then continues as if it had never stopped.
+//
+// The Trainer should be created with the same options as the Trainer that saved the checkpoint. The model, its config and the training set
+// come from the checkpoint, so the options that set them are ignored. The number of epochs passed to Train are the number of epochs to train
+// for after resuming; use Epoch to find out how many epochs have already been trained.
+func (t *Trainer) Resume(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to open checkpoint %q", filename)
+	}
+	return t.ResumeReader(f)
+}
+
+// ResumeReader initializes the Trainer from a checkpoint read from a io.ReadCloser. See Resume.
+func (t *Trainer) ResumeReader(rd io.ReadCloser) (err error) {
+	f := func() {
+		err = t.resume(rd)
+	}
+	t.once.Do(f)
+	return
+}
+
+func (t *Trainer) resume(rd io.ReadCloser) error {
+	defer rd.Close()
+	decoder := gob.NewDecoder(bufio.NewReader(rd))
+
+	if err := decoder.Decode(&t.corpus); err != nil {
+		return errors.Wrap(err, "Unable to decode the corpus")
+	}
+
+	nn := new(neuralnetwork2)
+	nn.dict = t.corpus
+	nn.costChan = t.nn.costChan
+	if err := decoder.Decode(nn); err != nil {
+		return errors.Wrap(err, "Unable to decode the neural network")
+	}
+	nn.rng = t.rng
+	t.nn = nn
+	t.ts = nn.transitions
+
+	var cp checkpoint
+	if err := decoder.Decode(&cp); err != nil {
+		return errors.Wrap(err, "Unable to decode the state of the Trainer")
+	}
+	if err := nn.solver.GobDecode(cp.Solver); err != nil {
+		return errors.Wrap(err, "Unable to decode the state of the solver")
+	}
+	nn.frozen = cp.Frozen
+
+	t.epoch = cp.Epoch
+	t.best = cp.Best
+	t.src.restore(cp.Seed, cp.Draws)
+	t.trainingSet = cp.TrainingSet
+
+	t.examples = nil
+	if cp.Order != nil {
+		examples := makeExamples(t.trainingSet, nn.NNConfig, nn.fs, nn.dict, t.ts, t)
+		if len(examples) != len(cp.Order) {
+			return errors.Errorf("The checkpoint has %d examples, but %d examples were made from its training set", len(cp.Order), len(examples))
+		}
+		t.examples = make([]example, len(cp.Order))
+		for i, id := range cp.Order {
+			if id < 0 || id >= len(examples) {
+				return errors.Errorf("Invalid example %d in the checkpoint", id)
+			}
+			t.examples[i] = examples[id]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package dep
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+)
+
+func TestCountingSource(t *testing.T) {
+	src := newCountingSource(1337)
+	r := rand.New(src)
+	for i := 0; i < 100; i++ {
+		r.Intn(i + 1)
+	}
+	r.Float64()
+
+	src2 := newCountingSource(0)
+	src2.restore(src.seed, src.draws)
+	r2 := rand.New(src2)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, r.Int63(), r2.Int63())
+	}
+}
+
+func TestTrainer_Resume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+
+	cases := []struct {
+		name   string
+		system TransitionSystem
+		opts   []TrainerConsOpt
+	}{
+		{"static", ArcStandard, nil},
+		{"dynamic", ArcEager, []TrainerConsOpt{WithDynamicOracle(ConstantExploration(0.5, 1))}},
+	}
+	for _, c := range cases {
+		conf.TransitionSystem = c.system
+		filename := filepath.Join(dir, c.name)
+		opts := append([]TrainerConsOpt{WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts)}, c.opts...)
+
+		// uninterrupted
+		trainer := NewTrainer(append(opts, WithSeed(1337), WithCheckpoints(filename, 2))...)
+		if err := trainer.Init(); err != nil {
+			t.Fatalf("%v: %+v", c.name, err)
+		}
+		if err := trainer.Train(3); err != nil {
+			t.Fatalf("%v: %+v", c.name, err)
+		}
+		if err := trainer.Train(2); err != nil {
+			t.Fatalf("%v: %+v", c.name, err)
+		}
+		assert.Equal(t, 5, trainer.Epoch())
+
+		// resumed from the checkpoint after the 4th epoch. The seed is restored from the checkpoint
+		resumed := NewTrainer(append(opts, WithSeed(1))...)
+		if err := resumed.Resume(filename); err != nil {
+			t.Fatalf("%v: %+v", c.name, err)
+		}
+		assert.Equal(t, 4, resumed.Epoch(), c.name)
+		if err := resumed.Train(1); err != nil {
+			t.Fatalf("%v: %+v", c.name, err)
+		}
+
+		want := trainer.nn.serialized()
+		got := resumed.nn.serialized()
+		for i := range want {
+			if !G.ValueEq(want[i].Value(), got[i].Value()) {
+				t.Errorf("%v: Expected %v to be the same as training without stopping", c.name, want[i].Name())
+			}
+		}
+	}
+
+	// resuming from a checkpoint that doesn't exist
+	if err := NewTrainer().Resume(filepath.Join(dir, "nothing")); err == nil {
+		t.Error("Expected an error resuming from a checkpoint that doesn't exist")
+	}
+
+	// a checkpoint that fails to save leaves nothing behind
+	filename := filepath.Join(dir, "uninitialized")
+	if err := NewTrainer().SaveCheckpoint(filename); err == nil {
+		t.Error("Expected an error checkpointing a Trainer that hasn't been initialized")
+	}
+	for _, f := range []string{filename, filename + ".tmp"} {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("Expected %q not to exist", f)
+		}
+	}
+}
@@ -21,6 +21,15 @@ type configuration struct {
 	buffer []head
 
 	bp int // buffer pointer - starts at 0, increments
+
+	system TransitionSystem
+	joint  bool // Shift tags the shifted word
+
+	// used by the swap oracle
+	swapOrder []int // the projective order of the gold tree
+	mpc       []int // the maximal projective components of the gold tree
+
+	cons *constraints // the constraints of a constrained parse
 }
 
 func newConfiguration(sentence lingo.AnnotatedSentence, fromGold bool) *configuration {
@@ -47,6 +56,18 @@ func newConfiguration(sentence lingo.AnnotatedSentence, fromGold bool) *configur
 	}
 }
 
+// setSystem sets the transition system that the configuration is transitioned with.
+// When tagging jointly, the POSTags of the words are cleared, and each word is tagged as it is shifted.
+func (c *configuration) setSystem(system TransitionSystem, joint bool) {
+	c.system = system
+	c.joint = joint
+	if joint {
+		for i := 1; i < c.WordCount(); i++ {
+			c.Annotation(i).POSTag = lingo.X
+		}
+	}
+}
+
 func (c *configuration) String() string {
 	return fmt.Sprintf("Stack: %v Buffer(%d): %v", c.stack, c.bp, c.buffer[c.bp:])
 }
@@ -201,6 +222,55 @@ func (c *configuration) isTerminal() bool {
 	return c.stackSize() == 1 && c.bufferSize() == 0
 }
 
+// budget returns the most transitions the configuration should need to be parsed
+func (c *configuration) budget() int { return c.system.budget(c.WordCount() - 1) }
+
+// repair turns the arcs of a configuration that couldn't be parsed to the end into a single rooted tree.
+// The words without heads are the roots of partial trees. If no word is attached to the root, the first of them on the stack
+// (or else the first of them) is attached to the root, and the others are attached to it. It returns the words that were attached.
+func (c *configuration) repair() (retVal []int) {
+	root := c.rootChild()
+	if root < 0 {
+		for _, w := range c.stack {
+			if w > 0 && c.Head(int(w)) < 0 {
+				root = int(w)
+				break
+			}
+		}
+	}
+	for i := 1; i < c.WordCount() && root < 0; i++ {
+		if c.Head(i) < 0 {
+			root = i
+		}
+	}
+	if root < 0 {
+		return nil
+	}
+
+	if c.Head(root) < 0 {
+		c.AddArc(0, root, lingo.Root)
+		retVal = append(retVal, root)
+	}
+	for i := 1; i < c.WordCount(); i++ {
+		if c.Head(i) < 0 {
+			c.AddArc(root, i, lingo.Dep)
+			retVal = append(retVal, i)
+		}
+	}
+	return retVal
+}
+
+// tarpit repairs a configuration that couldn't be parsed to the end, and returns the TarpitError that describes it
+func (c *configuration) tarpit(transitions int) TarpitError {
+	logf("TARPIT")
+	return TarpitError{
+		configuration: c,
+		Transitions:   transitions,
+		Budget:        c.budget(),
+		Repaired:      c.repair(),
+	}
+}
+
 // Actual Transitioning stuff
 func (c *configuration) shift() bool {
 	i := c.bufferValue(0)
@@ -213,3 +283,38 @@ func (c *configuration) shift() bool {
 	c.stack = append(c.stack, i) // push to it.... gotta work the pop
 	return true
 }
+
+// clone creates a copy of the configuration that can be transitioned independently of the original
+func (c *configuration) clone() *configuration {
+	dep := cloneDependency(c.Dependency)
+
+	stack := make([]head, len(c.stack))
+	copy(stack, c.stack)
+	buffer := make([]head, len(c.buffer))
+	copy(buffer, c.buffer)
+
+	return &configuration{
+		Dependency: dep,
+		stack:      stack,
+		buffer:     buffer,
+		bp:         c.bp,
+		system:     c.system,
+		joint:      c.joint,
+		swapOrder:  c.swapOrder,
+		mpc:        c.mpc,
+		cons:       c.cons,
+	}
+}
+
+// cloneDependency creates a copy of the tree that can be changed independently of the original
+func cloneDependency(d *lingo.Dependency) *lingo.Dependency {
+	sentence := d.AnnotatedSentence.Clone()
+	dep := lingo.NewDependency(lingo.FromAnnotatedSentence(sentence), lingo.AllocTree())
+	dep.SetID()
+	for i := 1; i < d.WordCount(); i++ {
+		if h := d.Head(i); h >= 0 {
+			dep.AddArc(h, i, d.Label(i))
+		}
+	}
+	return dep
+}
@@ -55,3 +55,58 @@ func TestConfiguration_StackValue(t *testing.T) {
 	assert.Equal(DOES_NOT_EXIST, negone, "NegOne value not the same")
 
 }
+
+func TestTransitionSystem_budget(t *testing.T) {
+	for _, system := range []TransitionSystem{ArcStandard, ArcEager, ArcSwap} {
+		for _, st := range allSentences() {
+			s := st.AnnotatedSentence(dummyFix{})
+			gold := s.Dependency()
+			if system.projective() && !gold.IsProjective() {
+				continue
+			}
+			c := newConfiguration(s, true)
+			c.setSystem(system, false)
+
+			var count int
+			for ; !c.isTerminal() && count < 1000; count++ {
+				c.apply(c.oracle(gold))
+			}
+			if count > c.budget() {
+				t.Errorf("%v: %d transitions are over the budget of %d", system, count, c.budget())
+			}
+		}
+	}
+}
+
+func TestConfiguration_repair(t *testing.T) {
+	s := simpleSentence()[0].AnnotatedSentence(dummyFix{})
+	c := newConfiguration(s, true)
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	c.apply(transition{Left, lingo.NSubj, lingo.X}) // 2 → 1
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+
+	err := c.tarpit(5)
+	assert.Equal(t, 5, err.Transitions)
+	assert.Equal(t, 20, err.Budget)
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10}, err.Repaired)
+	assert.Equal(t, "Tarpit Error: stopped after 5 transitions (budget 20). 9 words were attached by the repair", err.Error())
+
+	// a single rooted tree
+	assert.Equal(t, 2, c.rootChild())
+	assert.Equal(t, lingo.Root, c.Label(2))
+	assert.Equal(t, 2, c.Head(1), "the arcs of the parser are kept")
+	for i := 1; i < c.WordCount(); i++ {
+		if i != 2 {
+			assert.Equal(t, 2, c.Head(i))
+		}
+	}
+
+	// a word already attached to the root is the root of the repaired tree
+	c = newConfiguration(s, true)
+	c.apply(transition{Shift, lingo.NoDepType, lingo.X})
+	c.AddArc(0, 1, lingo.Root)
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10}, c.repair())
+	assert.Equal(t, 1, c.Head(10))
+}
@@ -0,0 +1,208 @@
+package dep
+
+import (
+	"fmt"
+
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+)
+
+// Constraints are parts of the structure of a sentence that are already known. When they are passed to Parse with WithConstraints,
+// the parser only builds trees that satisfy them.
+//
+// Words are identified by their index in the annotated sentence, where the root is 0.
+type Constraints struct {
+	Required  []ArcConstraint  // arcs that must be in the tree
+	Forbidden []ArcConstraint  // arcs that must not be in the tree
+	Spans     []SpanConstraint // spans of words that must form a subtree
+}
+
+// ArcConstraint is an arc from Head to Dependent. A Label of lingo.NoDepType matches any label.
+type ArcConstraint struct {
+	Head      int
+	Dependent int
+	Label     lingo.DependencyType
+}
+
+func (a ArcConstraint) matches(h, d int, label lingo.DependencyType) bool {
+	return a.Head == h && a.Dependent == d && (a.Label == lingo.NoDepType || a.Label == label)
+}
+
+// SpanConstraint is a span of words from Start to End (exclusive) that must form a subtree:
+// every word of the span except one is attached to a word within the span. Words outside the span may be attached to any word of it.
+type SpanConstraint struct {
+	Start int
+	End   int
+}
+
+func (s SpanConstraint) contains(i int) bool { return i >= s.Start && i < s.End }
+
+// WithConstraints makes Parse only build trees that satisfy the constraints. If the parser can't find such a tree, Parse returns a ConstraintError.
+// A beam search is more likely to find one than the greedy parser.
+func WithConstraints(cons Constraints) ParseOpt {
+	f := func(o *parseOpts) {
+		o.constraints = &cons
+	}
+	return f
+}
+
+// ConstraintError is the error when the parser can't find a tree that satisfies the Constraints.
+// It holds the best tree the parser found, which doesn't satisfy them.
+type ConstraintError struct {
+	*lingo.Dependency
+	Reason string
+}
+
+func (err ConstraintError) Error() string {
+	return fmt.Sprintf("No parse satisfies the constraints: %s", err.Reason)
+}
+
+// constraints are the Constraints of a sentence, checked against its length and indexed by word
+type constraints struct {
+	Constraints
+	heads  []int                  // the required head of each word, or -1
+	labels []lingo.DependencyType // the required label of each word
+}
+
+// compile checks that the constraints are valid for a sentence of n words, including the root
+func (cons Constraints) compile(n int) (*constraints, error) {
+	c := &constraints{
+		Constraints: cons,
+		heads:       make([]int, n),
+		labels:      make([]lingo.DependencyType, n),
+	}
+	for i := range c.heads {
+		c.heads[i] = -1
+	}
+
+	arc := func(a ArcConstraint) error {
+		if a.Head < 0 || a.Head >= n || a.Dependent < 1 || a.Dependent >= n || a.Head == a.Dependent {
+			return errors.Errorf("Invalid arc %d → %d in a sentence of %d words", a.Head, a.Dependent, n-1)
+		}
+		return nil
+	}
+	for _, a := range cons.Required {
+		if err := arc(a); err != nil {
+			return nil, err
+		}
+		if c.heads[a.Dependent] >= 0 && c.heads[a.Dependent] != a.Head {
+			return nil, errors.Errorf("Word %d is required to have two heads: %d and %d", a.Dependent, c.heads[a.Dependent], a.Head)
+		}
+		c.heads[a.Dependent] = a.Head
+		c.labels[a.Dependent] = a.Label
+	}
+	for _, a := range cons.Forbidden {
+		if err := arc(a); err != nil {
+			return nil, err
+		}
+		if a.Head == c.heads[a.Dependent] && (a.Label == lingo.NoDepType || a.Label == c.labels[a.Dependent]) {
+			return nil, errors.Errorf("The arc %d → %d is both required and forbidden", a.Head, a.Dependent)
+		}
+	}
+	for _, s := range cons.Spans {
+		if s.Start < 1 || s.End > n || s.Start >= s.End {
+			return nil, errors.Errorf("Invalid span [%d, %d) in a sentence of %d words", s.Start, s.End, n-1)
+		}
+	}
+
+	// the required arcs must not form a cycle
+	for i := 1; i < n; i++ {
+		h := c.heads[i]
+		for steps := 0; h > 0; steps++ {
+			if h == i || steps >= n {
+				return nil, errors.Errorf("The required arcs form a cycle through word %d", i)
+			}
+			h = c.heads[h]
+		}
+	}
+	return c, nil
+}
+
+// allows checks if the transition can be applied to the configuration without violating the constraints
+func (cons *constraints) allows(c *configuration, t transition) bool {
+	h, d, done := c.effect(t)
+
+	if d > 0 {
+		label := t.DependencyType
+		if t.Move == Reduce {
+			// the buffer is empty, and s0 is attached to whatever is below it
+			label = lingo.Dep
+			if h == 0 {
+				label = lingo.Root
+			}
+		}
+
+		if rh := cons.heads[d]; rh >= 0 && (rh != h || (cons.labels[d] != lingo.NoDepType && cons.labels[d] != label)) {
+			return false
+		}
+		for _, a := range cons.Forbidden {
+			if a.matches(h, d, label) {
+				return false
+			}
+		}
+		for _, s := range cons.Spans {
+			if s.contains(d) && !s.contains(h) && cons.attachedOut(c, s) {
+				return false
+			}
+		}
+	}
+
+	// the word that is popped can't take the dependents it is required to have
+	if done > 0 {
+		for i, rh := range cons.heads {
+			if rh == done && i != d && c.Head(i) < 0 {
+				return false
+			}
+		}
+	}
+
+	// in the arc-eager system, once b0 is pushed onto the stack, it can't take a head from the stack, nor dependents from the stack
+	if c.system == ArcEager && (t.Move == Shift || t.Move == Right) {
+		b0 := int(c.bufferValue(0))
+		if t.Move == Shift && cons.heads[b0] >= 0 && cons.heads[b0] < b0 {
+			return false
+		}
+		for _, w := range c.stack {
+			if cons.heads[w] == b0 && c.Head(int(w)) < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// attachedOut checks if a word of the span is attached to a word outside it
+func (cons *constraints) attachedOut(c *configuration, s SpanConstraint) bool {
+	for i := s.Start; i < s.End; i++ {
+		if h := c.Head(i); h >= 0 && !s.contains(h) {
+			return true
+		}
+	}
+	return false
+}
+
+// check checks that the tree satisfies the constraints. It returns a description of the first constraint that is violated, or "" if all are satisfied.
+func (cons *constraints) check(d *lingo.Dependency) string {
+	for _, a := range cons.Required {
+		if !a.matches(d.Head(a.Dependent), a.Dependent, d.Label(a.Dependent)) {
+			return fmt.Sprintf("the required arc %d → %d (%v) is missing", a.Head, a.Dependent, a.Label)
+		}
+	}
+	for _, a := range cons.Forbidden {
+		if a.matches(d.Head(a.Dependent), a.Dependent, d.Label(a.Dependent)) {
+			return fmt.Sprintf("the forbidden arc %d → %d (%v) is in the tree", a.Head, a.Dependent, a.Label)
+		}
+	}
+	for _, s := range cons.Spans {
+		var out int
+		for i := s.Start; i < s.End; i++ {
+			if !s.contains(d.Head(i)) {
+				out++
+			}
+		}
+		if out != 1 {
+			return fmt.Sprintf("the span [%d, %d) is not a subtree", s.Start, s.End)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package dep
+
+import (
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraints_compile(t *testing.T) {
+	bad := []Constraints{
+		{Required: []ArcConstraint{{Head: 10, Dependent: 1}}},
+		{Required: []ArcConstraint{{Head: 1, Dependent: 0}}},
+		{Required: []ArcConstraint{{Head: 1, Dependent: 2}, {Head: 3, Dependent: 2}}},
+		{Required: []ArcConstraint{{Head: 1, Dependent: 2}}, Forbidden: []ArcConstraint{{Head: 1, Dependent: 2}}},
+		{Required: []ArcConstraint{{Head: 1, Dependent: 2}, {Head: 2, Dependent: 3}, {Head: 3, Dependent: 1}}},
+		{Spans: []SpanConstraint{{Start: 3, End: 3}}},
+		{Spans: []SpanConstraint{{Start: 0, End: 3}}},
+	}
+	for i, cons := range bad {
+		if _, err := cons.compile(6); err == nil {
+			t.Errorf("%d: Expected an error compiling invalid constraints %v", i, cons)
+		}
+	}
+
+	cons := Constraints{
+		Required:  []ArcConstraint{{Head: 1, Dependent: 2, Label: lingo.Compound}},
+		Forbidden: []ArcConstraint{{Head: 1, Dependent: 2, Label: lingo.Dep}},
+	}
+	c, err := cons.compile(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []int{-1, -1, 1, -1, -1, -1}, c.heads)
+}
+
+func TestConstraints_allows(t *testing.T) {
+	// Yet we did n't charge them for the evacuation .
+	s := simpleSentence()[0].AnnotatedSentence(dummyFix{})
+	left := transition{Left, lingo.Dep, lingo.X}
+	right := transition{Right, lingo.Dep, lingo.X}
+	shift := transition{Shift, lingo.NoDepType, lingo.X}
+
+	cons := Constraints{
+		Required:  []ArcConstraint{{Head: 1, Dependent: 2}},
+		Forbidden: []ArcConstraint{{Head: 3, Dependent: 2}},
+		Spans:     []SpanConstraint{{Start: 4, End: 6}},
+	}
+	c := newConfiguration(s, true)
+	c.setSystem(ArcStandard, false)
+	var err error
+	if c.cons, err = cons.compile(c.WordCount()); err != nil {
+		t.Fatal(err)
+	}
+
+	c.apply(shift)
+	c.apply(shift)
+	assert.False(t, c.canApply(left), "word 1 can't be popped before word 2 is attached to it")
+	assert.True(t, c.canApply(right))
+	c.apply(right)
+
+	c.apply(shift)
+	c.apply(shift)
+	blocked := c.clone()
+	c.apply(shift)
+	c.apply(left)  // 5 → 4
+	c.apply(right) // 3 → 5
+	c.apply(shift)
+	c.apply(right) // 3 → 6
+	c.apply(right) // 1 → 3
+	assert.Equal(t, "", c.cons.check(c.Dependency))
+
+	// only one word of the span can be attached outside it
+	blocked.apply(right) // 3 → 4
+	blocked.apply(shift)
+	assert.True(t, blocked.canApply(left), "word 3 can be attached to a word of the span")
+	assert.False(t, blocked.canApply(right), "word 5 can't be attached to word 3")
+
+	// the forbidden arc
+	c = newConfiguration(s, true)
+	c.setSystem(ArcStandard, false)
+	c.cons, _ = cons.compile(c.WordCount())
+	c.apply(shift)
+	c.apply(shift)
+	c.apply(shift)
+	assert.False(t, c.canApply(left), "3 → 2 is forbidden")
+}
+
+func TestParser_Constraints(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.Dropout = 0
+
+	for _, system := range []TransitionSystem{ArcStandard, ArcEager} {
+		conf.TransitionSystem = system
+		trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+		if err := trainer.Init(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if err := trainer.Train(1); err != nil {
+			t.Fatalf("%+v", err)
+		}
+
+		p := New(trainer.Model)
+		for _, st := range sts {
+			s := st.AnnotatedSentence(dummyFix{})
+			gold := st.Dependency(dummyFix{})
+
+			// the gold arcs of the first few words, and the arcs the parser prefers instead of them
+			var cons Constraints
+			unconstrained, err := p.Parse(s)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			for i := 1; i < 4; i++ {
+				cons.Required = append(cons.Required, ArcConstraint{Head: gold.Head(i), Dependent: i, Label: gold.Label(i)})
+				if h := unconstrained.Head(i); h >= 0 && h != gold.Head(i) {
+					cons.Forbidden = append(cons.Forbidden, ArcConstraint{Head: h, Dependent: i})
+				}
+			}
+
+			for _, opts := range [][]ParseOpt{{WithConstraints(cons)}, {WithConstraints(cons), WithBeam(8)}} {
+				d, err := p.Parse(s, opts...)
+				if err != nil {
+					if _, ok := err.(ConstraintError); !ok {
+						t.Fatalf("%v: %+v", system, err)
+					}
+					continue
+				}
+				c, _ := cons.compile(d.WordCount())
+				assert.Equal(t, "", c.check(d), "%v: %q", system, d.ValueString())
+			}
+			if _, err = p.KBest(s, 8, WithConstraints(cons)); err != nil {
+				t.Errorf("%v: Expected the beam search to find a parse of %q satisfying the constraints: %v", system, s.ValueString(), err)
+			}
+
+			// no tree can have two words of a span attached outside the span
+			impossible := Constraints{
+				Required: []ArcConstraint{{Head: 3, Dependent: 1}, {Head: 3, Dependent: 2}},
+				Spans:    []SpanConstraint{{Start: 1, End: 3}},
+			}
+			for _, opts := range [][]ParseOpt{{WithConstraints(impossible)}, {WithConstraints(impossible), WithBeam(4)}} {
+				if _, err = p.Parse(s, opts...); err == nil {
+					t.Errorf("%v: Expected an error when the constraints can't be satisfied", system)
+				} else if _, ok := err.(ConstraintError); !ok {
+					t.Errorf("%v: Expected a ConstraintError. Got %v", system, err)
+				}
+			}
+		}
+
+		if _, err := p.Parse(sts[0].AnnotatedSentence(dummyFix{}), WithConstraints(Constraints{Spans: []SpanConstraint{{Start: 1, End: 100}}})); err == nil {
+			t.Errorf("%v: Expected an error with invalid constraints", system)
+		}
+	}
+}
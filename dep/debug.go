@@ -89,29 +89,27 @@ func (d *Parser) SprintFeatures(features []int) string {
 
 	var buf bytes.Buffer
 
-	for i := 0; i < 18; i++ {
-		number := features[i]
-		id := number - wordFeatsStartAt
-		word, _ := d.corpus.Word(id)
-
-		if word == "" {
-			word = "-NULL-"
+	var i int
+	for _, fts := range d.nn.fs.templates {
+		for _, ft := range fts {
+			number := features[i]
+			i++
+
+			var val interface{} = number
+			switch ft.Attribute.embedding() {
+			case wordEmbedding:
+				word, _ := d.corpus.Word(number - wordFeatsStartAt)
+				if word == "" {
+					word = "-NULL-"
+				}
+				val = fmt.Sprintf("%q", word)
+			case tagEmbedding:
+				val = lingo.POSTag(number)
+			case labelEmbedding:
+				val = lingo.DependencyType(number - labelFeatsStartAt)
+			}
+			buf.WriteString(fmt.Sprintf("%v, %v, %d\n", ft, val, number))
 		}
-
-		buf.WriteString(fmt.Sprintf("%d, %q, %d \n", feature(i), word, number))
-	}
-
-	for i := 0; i < 18; i++ {
-		number := features[i+18]
-
-		buf.WriteString(fmt.Sprintf("%d, %v, %d\n", feature(i+18), lingo.POSTag(number), number))
-	}
-
-	for i := 0; i < 12; i++ {
-		number := features[i+36]
-		id := number - labelFeatsStartAt
-
-		buf.WriteString(fmt.Sprintf("%d, %v, %d\n", feature(i+36), lingo.DependencyType(id), number))
 	}
 
 	return buf.String()
@@ -5,7 +5,6 @@ import (
 
 	"github.com/chewxy/lingo"
 	"github.com/chewxy/lingo/corpus"
-	"github.com/pkg/errors"
 )
 
 var KnownWords *corpus.Corpus // package provided global
@@ -13,13 +12,20 @@ var KnownWords *corpus.Corpus // package provided global
 // Parser is the object that performs the dependency parsing
 // It contains a neural network, which is the core of it.
 //
-// The same object can be used to train the NN
+// The same object can be used to train the NN.
+//
+// Parse is safe for concurrent use, and many Parsers can share one *Model. The weights of the model are only read when parsing;
+// each call to Parse keeps its own scratch space.
+//
+// Every parse is repaired by the Rules of the Parser. See AddRule and DisableRule.
 type Parser struct {
 	Input  chan lingo.AnnotatedSentence
 	Output chan *lingo.Dependency
 	Error  chan error
 
 	*Model
+
+	rules []ruleState
 }
 
 // New creates a new Parser
@@ -29,16 +35,22 @@ func New(m *Model) *Parser {
 		Error:  make(chan error),
 
 		Model: m,
+		rules: defaultRuleStates(),
 	}
 
 	return d
 }
 
-// Run is used when using the NN to parse a sentence. For training, see Train()
-func (d *Parser) Run() {
+// Run is used when using the NN to parse a sentence. For training, see Train(). The options are applied to every sentence parsed.
+// Sentences that end in a TarpitError are repaired, and output like any other sentence.
+func (d *Parser) Run(opts ...ParseOpt) {
 	defer close(d.Output)
 	for sentence := range d.Input {
-		dep, err := d.predict(sentence)
+		dep, err := d.Parse(sentence, opts...)
+		if tarpit, ok := err.(TarpitError); ok {
+			logf("%v: %q", tarpit, sentence.ValueString())
+			err = nil
+		}
 
 		if err != nil {
 			d.Error <- err
@@ -49,63 +61,128 @@ func (d *Parser) Run() {
 	return
 }
 
-func (d *Parser) predict(sentence lingo.AnnotatedSentence) (*lingo.Dependency, error) {
+// Parse parses a sentence. By default the sentence is parsed greedily. Use WithBeam to parse with beam search.
+//
+// The parse is always a single rooted tree. If the parser gets stuck, or runs out of its budget of transitions (which depends on the length
+// of the sentence), the words that haven't been attached are attached to the tree, and the tree is returned along with a TarpitError.
+func (d *Parser) Parse(sentence lingo.AnnotatedSentence, opts ...ParseOpt) (*lingo.Dependency, error) {
+	p, err := d.ParseScored(sentence, opts...)
+	if p == nil {
+		return nil, err
+	}
+	return p.Dependency, err
+}
+
+// ParseScored parses a sentence like Parse, and also returns the confidence of the parser in each arc of the parse.
+func (d *Parser) ParseScored(sentence lingo.AnnotatedSentence, opts ...ParseOpt) (*ScoredParse, error) {
+	var o parseOpts
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.beam > 1 {
+		ps, err := d.beamSearch(sentence, o)
+		if ps == nil {
+			return nil, err
+		}
+		return ps[0], err
+	}
+	return d.predict(sentence, o)
+}
+
+// KBest parses a sentence with a beam search of size k, and returns up to k different parses. The parses are ranked by the mean
+// log-probability of the transitions that built them, so parses that took more transitions (such as the swaps of ArcSwap) aren't penalized.
+// WithBeam is ignored. Like Parse, if none of the parses could be completed, a repaired parse is returned with a TarpitError.
+func (d *Parser) KBest(sentence lingo.AnnotatedSentence, k int, opts ...ParseOpt) ([]*ScoredParse, error) {
+	var o parseOpts
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.beam = k
+	if o.beam < 1 {
+		o.beam = 1
+	}
+	return d.beamSearch(sentence, o)
+}
+
+// start creates the initial configuration of a parse
+func (d *Parser) start(sentence lingo.AnnotatedSentence, o parseOpts) (*configuration, error) {
+	c := newConfiguration(sentence, hasHeads(sentence))
+	c.setSystem(d.nn.TransitionSystem, d.nn.JointTagging)
+	if o.constraints != nil {
+		var err error
+		if c.cons, err = o.constraints.compile(c.WordCount()); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (d *Parser) predict(sentence lingo.AnnotatedSentence, o parseOpts) (*ScoredParse, error) {
 	// defer func() {
 	// 	if r := recover(); r != nil {
 	// 		log.Printf("Parsing for %q", sentence.ValueString())
 	// 		panic(r)
 	// 	}
 	// }()
-	c := newConfiguration(sentence, false)
+	c, err := d.start(sentence, o)
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
-	var argmax int
+	sc, err := d.nn.newScratch()
+	if err != nil {
+		return nil, err
+	}
+
+	s := newArcScores(c)
+	budget := c.budget()
 	var count int
-	for !c.isTerminal() && count < 100 {
+	var stuck bool
+	for !c.isTerminal() && count < budget && !stuck {
 		logf("%v", c)
-		if count == 99 {
-			logf("TARPIT")
-		}
 
-		features := getFeatures(c, d.corpus)
+		features := d.nn.fs.extract(c, d.corpus)
 		// features2 := getFeatureArray(c, d.dict)
 
-		if argmax, err = d.nn.pred(features); err != nil {
-			return nil, err
+		j := sc.pred(features)
+		logSoftmax(sc.scores)
+		if !c.canApply(d.ts[j]) {
+			j = bestApplicableIndex(c, d.ts, sc.scores)
 		}
-		// log.Printf("Argmax: %v, len(d.ts): %v, len(transitions) %v", argmax, len(d.ts), len(transitions))
-		t := transitions[argmax] // no this is NOT a mistake
-		if !c.canApply(t) {
-			t = transition{Shift, lingo.NoDepType} // reset
-			// manual argmaxing
-			switch scores := d.nn.scores.Value().Data().(type) {
-			case []float32:
-				var maxScore float32
-				for i, kt := range d.ts {
-					if scores[i] > maxScore && c.canApply(kt) {
-						maxScore = scores[i]
-						t = kt
-					}
-				}
-			case []float64:
-				var maxScore float64
-				for i, kt := range d.ts {
-					if scores[i] > maxScore && c.canApply(kt) {
-						maxScore = scores[i]
-						t = kt
-					}
-				}
-			default:
-				return nil, errors.Errorf("Unhandled score type %T", d.nn.scores.Value())
-			}
-
+		if j < 0 {
+			stuck = true
+			continue
 		}
-		c.apply(t)
+		s.apply(c, d.ts[j], sc.scores[j], alternative(c, d.ts, sc.scores, j))
 
 		count++
 	}
-	fix(c.Dependency)
-	return c.Dependency, err
+
+	if stuck && c.cons != nil {
+		return nil, ConstraintError{c.Dependency, "the parser is stuck"}
+	}
+	var tarpit error
+	if !c.isTerminal() {
+		tarpit = c.tarpit(count)
+	}
+	if c.cons != nil {
+		if reason := c.cons.check(c.Dependency); reason != "" {
+			return nil, ConstraintError{c.Dependency, reason}
+		}
+	}
+	return s.finish(c, d), tarpit
+}
+
+// hasHeads checks if the sentence already has heads (for example, when a gold sentence is parsed).
+// They would confuse the transition system, so such sentences are copied before parsing.
+func hasHeads(sentence lingo.AnnotatedSentence) bool {
+	for _, a := range sentence {
+		if a.Head != nil {
+			return true
+		}
+	}
+	return false
 }
 
 func (d *Parser) String() string {
@@ -0,0 +1,120 @@
+package dep
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+// Run with the race detector. The tensor package trips checkptr, so it has to be turned off:
+//
+//	go test -race -gcflags=all=-d=checkptr=0 -run TestParser_concurrent
+func TestParser_concurrent(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := trainer.Train(1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	shared := New(trainer.Model)
+	want := make([][]int, len(sts))
+	for i, st := range sts {
+		d, err := shared.Parse(st.AnnotatedSentence(dummyFix{}))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		want[i] = d.Heads()
+	}
+	trainer.nn.invalidate() // so that the precomputed network is built concurrently too
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		p := shared
+		if g%2 == 0 {
+			p = New(trainer.Model)
+		}
+		var opts []ParseOpt
+		if g%4 < 2 {
+			opts = append(opts, WithBeam(2))
+		}
+
+		wg.Add(1)
+		go func(p *Parser, opts []ParseOpt) {
+			defer wg.Done()
+			for i, st := range sts {
+				d, err := p.Parse(st.AnnotatedSentence(dummyFix{}), opts...)
+				if err != nil {
+					t.Errorf("%+v", err)
+					return
+				}
+				if len(opts) == 0 {
+					assert.Equal(t, want[i], d.Heads())
+				}
+			}
+		}(p, opts)
+	}
+	wg.Wait()
+}
+
+func TestParser_longSentence(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := trainer.Train(1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// all the sentences, twice, as one sentence of more than 100 words
+	s := lingo.AnnotatedSentence{lingo.RootAnnotation()}
+	for i := 0; i < 2; i++ {
+		for _, st := range sts {
+			for j, lex := range st.Sentence {
+				s = append(s, lingo.AnnotationFromLexTag(lex, st.Tags[j], dummyFix{}))
+			}
+		}
+	}
+	if len(s) <= 101 {
+		t.Fatalf("Expected a sentence of more than 100 words. Got %d", len(s)-1)
+	}
+
+	p := New(trainer.Model)
+	for _, opts := range [][]ParseOpt{nil, {WithBeam(2)}} {
+		d, err := p.Parse(s, opts...)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		var roots int
+		for i := 1; i < d.WordCount(); i++ {
+			switch h := d.Head(i); {
+			case h < 0:
+				t.Errorf("Expected word %d to have a head", i)
+			case h == 0:
+				roots++
+			}
+		}
+		assert.Equal(t, 1, roots)
+	}
+
+	ds, err := p.ParseBatch([]lingo.AnnotatedSentence{s})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for i := 1; i < ds[0].WordCount(); i++ {
+		if ds[0].Head(i) < 0 {
+			t.Errorf("Expected word %d to have a head", i)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package dep
+
+import (
+	"math"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+	"github.com/pkg/errors"
+)
+
+// ExplorationSchedule returns the probability that, when training with a dynamic oracle, the parser follows its own
+// prediction instead of the oracle in the given epoch (starting from 0).
+type ExplorationSchedule func(epoch int) float64
+
+// ConstantExploration explores with the same probability every epoch, after warmup epochs of following the oracle.
+func ConstantExploration(p float64, warmup int) ExplorationSchedule {
+	return func(epoch int) float64 {
+		if epoch < warmup {
+			return 0
+		}
+		return p
+	}
+}
+
+// LinearExploration explores with a probability that increases linearly from start to end over the given number of epochs.
+// Afterwards the probability stays at end.
+func LinearExploration(start, end float64, epochs int) ExplorationSchedule {
+	return func(epoch int) float64 {
+		if epoch >= epochs || epochs <= 1 {
+			return end
+		}
+		return start + (end-start)*float64(epoch)/float64(epochs-1)
+	}
+}
+
+// hasDynamicOracle returns true if the transition system can compute the cost of any transition from any configuration.
+// ArcSwap has none: it reorders the words to build non-projective trees, and there is no known way to compute the cost of a
+// swap exactly in polynomial time, so it is always trained with its static oracle.
+func (ts TransitionSystem) hasDynamicOracle() bool { return ts == ArcStandard || ts == ArcEager }
+
+// cost returns the number of gold arcs (and gold tags, when tagging jointly) that can no longer be built if the transition is applied
+func (c *configuration) cost(t transition, goldParse *lingo.Dependency) int {
+	return c.costFunc(goldParse)(t)
+}
+
+// costFunc returns a function that computes the cost of transitions from the configuration as it is now.
+// The configuration must not change while the function is in use.
+func (c *configuration) costFunc(goldParse *lingo.Dependency) func(transition) int {
+	var cost func(transition) int
+	switch c.system {
+	case ArcEager:
+		cost = func(t transition) int { return c.arcEagerCost(t, goldParse) }
+	case ArcStandard:
+		cost = c.arcStandardCostFunc(goldParse)
+	default:
+		panic(errors.Errorf("%v has no dynamic oracle", c.system))
+	}
+	if !c.joint {
+		return cost
+	}
+
+	// a shift that tags the word wrongly costs the tag
+	return func(t transition) int {
+		retVal := cost(t)
+		if t.Move == Shift && t.POSTag != goldTag(goldParse.Annotation(int(c.bufferValue(0))).POSTag) {
+			retVal++
+		}
+		return retVal
+	}
+}
+
+// bestTransitions returns the applicable transitions (as indices into ts) with the lowest cost.
+// These are the transitions that the dynamic oracle considers correct.
+func (c *configuration) bestTransitions(goldParse *lingo.Dependency, ts []transition) []int {
+	var retVal []int
+	minCost := math.MaxInt32
+	costOf := c.costFunc(goldParse)
+	for i, t := range ts {
+		if !c.canApply(t) {
+			continue
+		}
+
+		cost := costOf(t)
+		switch {
+		case cost < minCost:
+			minCost = cost
+			retVal = append(retVal[:0], i)
+		case cost == minCost:
+			retVal = append(retVal, i)
+		}
+	}
+	return retVal
+}
+
+// arcEagerCost is the exact cost of a transition in the arc-eager system, as described by Goldberg and Nivre in
+// "A Dynamic Oracle for Arc-Eager Dependency Parsing" (2012).
+func (c *configuration) arcEagerCost(t transition, goldParse *lingo.Dependency) int {
+	s0 := int(c.stackValue(0))
+	b0 := int(c.bufferValue(0))
+
+	var cost int
+	switch t.Move {
+	case Left:
+		// s0 can no longer get its head from the rest of the buffer, or its dependents from the buffer
+		for _, h := range c.buffer[c.bp:] {
+			k := int(h)
+			if k != b0 && goldParse.Head(s0) == k {
+				cost++
+			}
+			if goldParse.Head(k) == s0 {
+				cost++
+			}
+		}
+		if goldParse.Head(s0) == b0 && goldParse.Label(s0) != t.DependencyType {
+			cost++
+		}
+	case Right:
+		// b0 can no longer get its head from elsewhere, or its dependents from the stack
+		for _, h := range c.stack {
+			k := int(h)
+			if k != s0 && goldParse.Head(b0) == k {
+				cost++
+			}
+			if goldParse.Head(k) == b0 && c.Head(k) < 0 {
+				cost++
+			}
+		}
+		for _, h := range c.buffer[c.bp+1:] {
+			if goldParse.Head(b0) == int(h) {
+				cost++
+			}
+		}
+		if goldParse.Head(b0) == s0 && goldParse.Label(b0) != t.DependencyType {
+			cost++
+		}
+	case Reduce:
+		// s0 can no longer get its dependents from the buffer
+		for _, h := range c.buffer[c.bp:] {
+			if goldParse.Head(int(h)) == s0 {
+				cost++
+			}
+		}
+	case Shift:
+		// b0 can no longer get its head or its dependents from the stack
+		for _, h := range c.stack {
+			k := int(h)
+			if goldParse.Head(b0) == k {
+				cost++
+			}
+			if goldParse.Head(k) == b0 && c.Head(k) < 0 {
+				cost++
+			}
+		}
+	}
+	return cost
+}
+
+// arcStandardCostFunc returns a function that computes the exact cost of transitions in the arc-standard system, using the
+// tabular method described by Goldberg, Sartorio and Satta in "A Tabular Method for Dynamic Oracles in Transition-Based Parsing" (2014).
+//
+// Unlike in the arc-eager system, arcs can't be lost one at a time, so the cost of a transition is the difference between the
+// number of gold arcs reachable before and after it. All labelled transitions that make the same move share that difference,
+// so it is only computed once per move.
+func (c *configuration) arcStandardCostFunc(goldParse *lingo.Dependency) func(transition) int {
+	before := -1
+	after := make(map[Move]int)
+	return func(t transition) int {
+		if before < 0 {
+			before = reachableArcs(c.stack, c.buffer[c.bp:], goldParse)
+		}
+
+		h, d, done := c.effect(t)
+		reachable, ok := after[t.Move]
+		if !ok {
+			stack := make([]head, 0, len(c.stack)+1)
+			buffer := c.buffer[c.bp:]
+			for _, w := range c.stack {
+				if int(w) != done {
+					stack = append(stack, w)
+				}
+			}
+			if t.Move == Shift {
+				stack = append(stack, buffer[0])
+				buffer = buffer[1:]
+			}
+			reachable = reachableArcs(stack, buffer, goldParse)
+			after[t.Move] = reachable
+		}
+
+		cost := before - reachable
+		if h >= 0 && goldParse.Head(d) == h {
+			cost--
+			if goldParse.Label(d) != t.DependencyType {
+				cost++
+			}
+		}
+		return cost
+	}
+}
+
+// reachableArcs returns the largest number of gold arcs that can still be built between the words on the stack and the words
+// in the buffer of an arc-standard configuration.
+//
+// A word in the buffer whose gold head is also in the buffer, and which has no gold dependent on the stack, can always be
+// attached to its gold head, so it is counted without being parsed. The other words are parsed with Eisner's algorithm,
+// constrained to the trees the arc-standard system can still build: the words on the stack (except the root and the top) have
+// to be combined in the order they are on the stack. Such a word's nearest right dependent has to cover the top of the stack,
+// and if it takes no right dependent, it takes no left dependent either, and its head is to its right.
+func reachableArcs(stack, buffer []head, goldParse *lingo.Dependency) int {
+	n := goldParse.WordCount()
+	inBuffer := make([]bool, n)
+	for _, w := range buffer {
+		inBuffer[w] = true
+	}
+	headsStack := make([]bool, n) // the word has a gold dependent (or further descendant) on the stack
+	for _, w := range stack {
+		for h := goldParse.Head(int(w)); h > 0 && !headsStack[h]; h = goldParse.Head(h) {
+			headsStack[h] = true
+		}
+	}
+
+	var arcs int
+	words := make([]int, 0, len(stack)+len(buffer))
+	for _, w := range stack {
+		words = append(words, int(w))
+	}
+	for _, w := range buffer {
+		if inBuffer[goldParse.Head(int(w))] && !headsStack[w] {
+			arcs++
+			continue
+		}
+		words = append(words, int(w))
+	}
+	if len(words) == 1 {
+		return arcs
+	}
+	return arcs + arcStandardEisner(words, len(stack)-1, goldParse)
+}
+
+// arcStandardEisner returns the largest number of gold arcs in a tree over the words, rooted at words[0], where words[top] is the
+// top of the stack. See reachableArcs for the constraints on the words on the stack.
+func arcStandardEisner(words []int, top int, goldParse *lingo.Dependency) int {
+	const impossible = math.MinInt32 / 4
+	N := len(words)
+	score := func(h, d int) int {
+		if goldParse.Head(words[d]) == words[h] {
+			return 1
+		}
+		return 0
+	}
+	pinned := func(i int) bool { return i > 0 && i < top }
+
+	table := func() [][]int {
+		t := make([][]int, N)
+		for i := range t {
+			t[i] = make([]int, N)
+			for j := range t[i] {
+				if i != j {
+					t[i][j] = impossible
+				}
+			}
+		}
+		return t
+	}
+	// complete and incomplete spans, headed on the right (the leftward ones) or on the left (the rightward ones).
+	// leftBare holds the incomplete leftward spans whose dependent has no right dependents
+	right, left := table(), table()
+	rightInc, leftInc, leftBare := table(), table(), table()
+	for span := 1; span < N; span++ {
+		for i := 1; i+span < N; i++ {
+			j := i + span
+			for r := i; r < j; r++ {
+				v := right[i][r] + left[r+1][j]
+				rightInc[i][j] = maxInt(rightInc[i][j], v+score(i, j))
+				if r == i {
+					leftBare[i][j] = maxInt(leftBare[i][j], v+score(j, i))
+				} else {
+					leftInc[i][j] = maxInt(leftInc[i][j], v+score(j, i))
+				}
+			}
+
+			for m := i; m < j; m++ {
+				inc := leftInc[m][j]
+				if !pinned(m) || m == i {
+					inc = maxInt(inc, leftBare[m][j])
+				}
+				left[i][j] = maxInt(left[i][j], left[i][m]+inc)
+			}
+
+			if pinned(i) && j < top {
+				continue
+			}
+			for m := i + 1; m <= j; m++ {
+				if pinned(m) && m == j {
+					continue
+				}
+				right[i][j] = maxInt(right[i][j], rightInc[i][m]+right[m][j])
+			}
+		}
+	}
+
+	// the root takes exactly one dependent
+	best := impossible
+	for m := 1; m < N; m++ {
+		best = maxInt(best, left[1][m]+score(0, m)+right[m][N-1])
+	}
+	return best
+}
+
+// makeDynamicExamples makes examples by parsing the sentences with the current model.
+// With probability p, the parser follows its own prediction, even if it is wrong. Otherwise it follows the best transition according to the dynamic oracle.
+// The transition each example is trained on is the highest scoring of the best transitions.
+func (t *Trainer) makeDynamicExamples(p float64) ([]example, error) {
+	sc, err := t.nn.newScratch()
+	if err != nil {
+		return nil, err
+	}
+
+	var examples []example
+	for _, sentenceTag := range t.trainingSet {
+		exs, err := t.makeOneDynamicExample(sentenceTag, sc, p)
+		if err != nil {
+			switch err.(type) {
+			case TarpitError, NonProjectiveError:
+				continue
+			}
+			return nil, err
+		}
+		examples = append(examples, exs...)
+	}
+	logf("Number of dynamic examples: %d (exploration probability %v)", len(examples), p)
+	return examples, nil
+}
+
+func (t *Trainer) makeOneDynamicExample(sentenceTag treebank.SentenceTag, sc *scratch, p float64) ([]example, error) {
+	var examples []example
+	s := sentenceTag.AnnotatedSentence(t)
+	dep := s.Dependency()
+	if t.nn.TransitionSystem.projective() && !dep.IsProjective() {
+		return nil, NonProjectiveError{dep}
+	}
+
+	c := newConfiguration(s, true)
+	c.setSystem(t.nn.TransitionSystem, t.nn.JointTagging)
+	for count := 0; !c.isTerminal(); count++ {
+		if count == c.budget() {
+			return examples, c.tarpit(count)
+		}
+
+		features := t.nn.fs.extract(c, t.nn.dict)
+		sc.pred(features)
+		scores := sc.scores
+
+		best := c.bestTransitions(dep, t.ts)
+		if len(best) == 0 {
+			break
+		}
+		target := best[0]
+		for _, i := range best {
+			if scores[i] > scores[target] {
+				target = i
+			}
+		}
+
+		labels := make([]int, len(t.ts))
+		for i, tr := range t.ts {
+			labels[i] = -1
+			if c.canApply(tr) {
+				labels[i] = 0
+			}
+		}
+		for _, i := range best {
+			labels[i] = 1
+		}
+		examples = append(examples, example{transition: t.ts[target], features: features, labels: labels})
+
+		next := t.ts[target]
+		if t.rng.Float64() < p {
+			// explore: follow the prediction of the model
+			maxScore := math.Inf(-1)
+			for i, tr := range t.ts {
+				if scores[i] > maxScore && c.canApply(tr) {
+					maxScore = scores[i]
+					next = tr
+				}
+			}
+		}
+		c.apply(next)
+	}
+	return examples, nil
+}
@@ -0,0 +1,177 @@
+package dep
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArcEagerCost(t *testing.T) { testCost(t, ArcEager) }
+
+func TestArcStandardCost(t *testing.T) {
+	testCost(t, ArcStandard)
+
+	// we compare against every parse unless the short flag is passed in
+	if testing.Short() {
+		return
+	}
+
+	// the cost is exact: it is the number of gold arcs that the best parse reachable from the configuration loses
+	ts := []transition{{Shift, lingo.NoDepType, lingo.X}, {Left, lingo.Dep, lingo.X}, {Right, lingo.Dep, lingo.X}, {Right, lingo.Root, lingo.X}}
+	r := rand.New(rand.NewSource(1337))
+	for _, st := range allSentences() {
+		s := st.AnnotatedSentence(dummyFix{})
+		d := s.Dependency()
+		if len(s) > 11 {
+			continue // trying every parse of sentences longer than 10 words (and the root) takes too long
+		}
+
+		memo := make(map[string]int)
+		for walk := 0; walk < 50; walk++ {
+			c := newConfiguration(s, true)
+			c.system = ArcStandard
+			for !c.isTerminal() {
+				var applicable []transition
+				for _, tr := range ts {
+					if !c.canApply(tr) {
+						continue
+					}
+					applicable = append(applicable, tr)
+
+					next := c.clone()
+					next.apply(tr)
+					expected := mostGoldArcs(c, d, memo) - mostGoldArcs(next, d, memo)
+					if h, dep, _ := c.effect(tr); h >= 0 && d.Head(dep) == h && d.Label(dep) != tr.DependencyType {
+						expected++
+					}
+					if cost := c.cost(tr, d); cost != expected {
+						t.Fatalf("Expected %v to cost %d in %v. Got %d", tr, expected, c, cost)
+					}
+				}
+				c.apply(applicable[r.Intn(len(applicable))])
+			}
+		}
+	}
+}
+
+// mostGoldArcs returns the largest number of correct heads in a parse reachable from the configuration, by trying every parse
+func mostGoldArcs(c *configuration, goldParse *lingo.Dependency, memo map[string]int) int {
+	if c.isTerminal() {
+		var correct int
+		for i := 1; i < c.WordCount(); i++ {
+			if c.Head(i) == goldParse.Head(i) {
+				correct++
+			}
+		}
+		return correct
+	}
+
+	key := fmt.Sprint(c.stack, c.buffer[c.bp:], c.Heads())
+	if retVal, ok := memo[key]; ok {
+		return retVal
+	}
+	retVal := -1
+	for _, tr := range []transition{{Shift, lingo.NoDepType, lingo.X}, {Left, lingo.Dep, lingo.X}, {Right, lingo.Dep, lingo.X}, {Right, lingo.Root, lingo.X}} {
+		if !c.canApply(tr) {
+			continue
+		}
+		next := c.clone()
+		next.apply(tr)
+		retVal = maxInt(retVal, mostGoldArcs(next, goldParse, memo))
+	}
+	memo[key] = retVal
+	return retVal
+}
+
+func testCost(t *testing.T, system TransitionSystem) {
+	ts := system.transitions()
+	for _, st := range allSentences() {
+		s := st.AnnotatedSentence(dummyFix{})
+		d := s.Dependency()
+
+		// the static oracle never loses an arc
+		c := newConfiguration(s, true)
+		c.system = system
+		for count := 0; !c.isTerminal() && count < 1000; count++ {
+			oracle := c.oracle(d)
+			if cost := c.cost(oracle, d); cost != 0 {
+				t.Fatalf("The static oracle's %v costs %d in %v", oracle, cost, c)
+			}
+			c.apply(oracle)
+		}
+
+		// from any configuration, there are best transitions to follow
+		r := rand.New(rand.NewSource(1337))
+		c = newConfiguration(s, true)
+		c.system = system
+		for count := 0; !c.isTerminal(); count++ {
+			if count == 1000 {
+				t.Fatalf("Tarpit: %v", c)
+			}
+
+			best := c.bestTransitions(d, ts)
+			if len(best) == 0 {
+				t.Fatalf("No best transitions in %v", c)
+			}
+
+			next := ts[best[0]]
+			if r.Float64() < 0.3 {
+				var applicable []transition
+				for _, tr := range ts {
+					if c.canApply(tr) {
+						applicable = append(applicable, tr)
+					}
+				}
+				next = applicable[r.Intn(len(applicable))]
+			}
+			c.apply(next)
+		}
+	}
+}
+
+func TestLinearExploration(t *testing.T) {
+	schedule := LinearExploration(0, 0.5, 3)
+	assert.Equal(t, []float64{0, 0.25, 0.5, 0.5}, []float64{schedule(0), schedule(1), schedule(2), schedule(3)})
+
+	schedule = ConstantExploration(0.2, 1)
+	assert.Equal(t, []float64{0, 0.2, 0.2}, []float64{schedule(0), schedule(1), schedule(2)})
+}
+
+func TestTrainer_DynamicOracle(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+
+	conf.TransitionSystem = ArcSwap
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts), WithDynamicOracle(ConstantExploration(0.5, 1)))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := trainer.Train(2); err == nil {
+		t.Error("Expected an error when training the arc-swap system with a dynamic oracle")
+	}
+
+	for _, system := range []TransitionSystem{ArcStandard, ArcEager} {
+		conf.TransitionSystem = system
+		trainer = NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts), WithDynamicOracle(ConstantExploration(0.5, 1)))
+		if err := trainer.Init(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if err := trainer.Train(2); err != nil {
+			t.Fatalf("%v: %+v", system, err)
+		}
+
+		exs, err := trainer.makeDynamicExamples(1)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		for _, ex := range exs {
+			if ex.labels[lookupTransition(ex.transition, trainer.ts)] != 1 {
+				t.Errorf("%v: Expected %v to be one of the best transitions", system, ex.transition)
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package dep
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chewxy/lingo/corpus"
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+)
+
+// EmbeddingFormat is the format of a file of pretrained word embeddings
+type EmbeddingFormat byte
+
+const (
+	// Word2VecText is the text format of word2vec: a header line with the number of words and the number of dimensions,
+	// followed by one line per word of the word and its vector, separated by spaces.
+	Word2VecText EmbeddingFormat = iota
+
+	// Word2VecBinary is the binary format of word2vec: the same header line, followed by each word, a space and
+	// its vector as little endian float32s.
+	Word2VecBinary
+
+	// GloVe is the text format of GloVe. It is the same as Word2VecText, but without the header line.
+	GloVe
+
+	MAXEMBEDDINGFORMAT
+)
+
+var embeddingFormatNames = [...]string{"word2vec", "word2vec-binary", "glove"}
+
+func (f EmbeddingFormat) String() string {
+	if f >= MAXEMBEDDINGFORMAT {
+		return fmt.Sprintf("EmbeddingFormat(%d)", f)
+	}
+	return embeddingFormatNames[f]
+}
+
+// ParseEmbeddingFormat returns the EmbeddingFormat with the given name
+func ParseEmbeddingFormat(name string) (EmbeddingFormat, error) {
+	for i, n := range embeddingFormatNames {
+		if n == name {
+			return EmbeddingFormat(i), nil
+		}
+	}
+	return MAXEMBEDDINGFORMAT, errors.Errorf("Unknown embedding format %q", name)
+}
+
+// PretrainedOpt is an option for loading pretrained embeddings
+type PretrainedOpt func(p *pretrained)
+
+// AddPretrainedWords adds the words that are in the file of pretrained embeddings but not in the corpus to the corpus,
+// so the parser has embeddings for words it has never seen in training.
+func AddPretrainedWords() PretrainedOpt {
+	f := func(p *pretrained) {
+		p.addWords = true
+	}
+	return f
+}
+
+// FreezePretrained keeps the pretrained embeddings as they are while training. By default they are fine-tuned along with the rest of the model.
+func FreezePretrained() PretrainedOpt {
+	f := func(p *pretrained) {
+		p.freeze = true
+	}
+	return f
+}
+
+// pretrained holds the settings for initializing the word embeddings from pretrained vectors
+type pretrained struct {
+	filename string
+	format   EmbeddingFormat
+	addWords bool
+	freeze   bool
+}
+
+// WithPretrainedEmbeddings sets up a *Trainer to initialize the word embeddings from a file of pretrained vectors, instead of randomly.
+//
+// The embeddings of the words in the corpus that are in the file are replaced by the pretrained vectors. Words that are not found
+// are looked up in lower case, as many pretrained vectors are lower cased. The rest of the words keep their random embeddings.
+// The vectors must have as many dimensions as the EmbeddingSize of the config.
+//
+// The file is read when the Trainer is initialized.
+func WithPretrainedEmbeddings(filename string, format EmbeddingFormat, opts ...PretrainedOpt) TrainerConsOpt {
+	f := func(t *Trainer) {
+		p := &pretrained{
+			filename: filename,
+			format:   format,
+		}
+		for _, opt := range opts {
+			opt(p)
+		}
+		t.pretrained = p
+	}
+	return f
+}
+
+// load reads the pretrained vectors of the words in the dict, adding words to the dict if the option is set.
+// It returns the vectors by the IDs of the words.
+func (p *pretrained) load(dict *corpus.Corpus, dims int) (map[int][]float64, error) {
+	f, err := os.Open(p.filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to open pretrained embeddings %q", p.filename)
+	}
+	defer f.Close()
+
+	// the IDs of the words in the dict by their lower cased forms
+	lowered := make(map[string][]int)
+	for id := 0; id < dict.Size(); id++ {
+		w, _ := dict.Word(id)
+		lowered[strings.ToLower(w)] = append(lowered[strings.ToLower(w)], id)
+	}
+
+	retVal := make(map[int][]float64)
+	exact := make(map[int]bool)
+	fn := func(word string, vec []float64) error {
+		if len(vec) != dims {
+			return errors.Errorf("Expected the pretrained embedding of %q to have %d dimensions. Got %d", word, dims, len(vec))
+		}
+
+		for _, id := range lowered[word] {
+			if !exact[id] {
+				retVal[id] = vec
+			}
+		}
+		id, ok := dict.Id(word)
+		if !ok && p.addWords {
+			id, ok = dict.Add(word), true
+		}
+		if ok {
+			retVal[id] = vec
+			exact[id] = true
+		}
+		return nil
+	}
+	if err = readEmbeddings(f, p.format, fn); err != nil {
+		return nil, errors.Wrapf(err, "Unable to read pretrained embeddings %q", p.filename)
+	}
+	logf("%d/%d words have pretrained embeddings", len(retVal), dict.Size())
+	return retVal, nil
+}
+
+// readEmbeddings reads the word embeddings in the given format, calling fn on each of them.
+func readEmbeddings(r io.Reader, format EmbeddingFormat, fn func(word string, vec []float64) error) error {
+	br := bufio.NewReader(r)
+	switch format {
+	case Word2VecText, GloVe:
+		var line int
+		if format == Word2VecText {
+			if _, _, err := readEmbeddingHeader(br); err != nil {
+				return err
+			}
+			line++
+		}
+		for {
+			l, err := br.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			line++
+
+			if fields := strings.Fields(l); len(fields) > 0 {
+				vec := make([]float64, len(fields)-1)
+				for i, s := range fields[1:] {
+					if vec[i], err = strconv.ParseFloat(s, 64); err != nil {
+						return errors.Wrapf(err, "Line %d", line)
+					}
+				}
+				if err = fn(fields[0], vec); err != nil {
+					return errors.Wrapf(err, "Line %d", line)
+				}
+			}
+
+			if err == io.EOF {
+				return nil
+			}
+		}
+	case Word2VecBinary:
+		words, dims, err := readEmbeddingHeader(br)
+		if err != nil {
+			return err
+		}
+		buf := make([]byte, 4*dims)
+		for i := 0; i < words; i++ {
+			word, err := br.ReadString(' ')
+			if err != nil {
+				return errors.Wrapf(err, "Word %d", i)
+			}
+			word = strings.TrimSpace(word) // the vectors may be followed by a newline
+
+			if _, err = io.ReadFull(br, buf); err != nil {
+				return errors.Wrapf(err, "Word %d", i)
+			}
+			vec := make([]float64, dims)
+			for j := range vec {
+				vec[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*j:])))
+			}
+			if err = fn(word, vec); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("Unknown embedding format %v", format)
+	}
+}
+
+// readEmbeddingHeader reads the number of words and the number of dimensions from the header line of word2vec files
+func readEmbeddingHeader(br *bufio.Reader) (words, dims int, err error) {
+	var l string
+	if l, err = br.ReadString('\n'); err != nil {
+		return 0, 0, errors.Wrap(err, "Unable to read the header")
+	}
+	if _, err = fmt.Sscanf(l, "%d %d", &words, &dims); err != nil {
+		return 0, 0, errors.Wrapf(err, "Unable to read the header %q", strings.TrimSpace(l))
+	}
+	return
+}
+
+// setRows sets the rows of the matrix to the given vectors
+func setRows(v G.Value, rows map[int][]float64) error {
+	switch data := v.Data().(type) {
+	case []float32:
+		cols := len(data) / v.Shape()[0]
+		for r, vec := range rows {
+			for i, f := range vec {
+				data[r*cols+i] = float32(f)
+			}
+		}
+	case []float64:
+		cols := len(data) / v.Shape()[0]
+		for r, vec := range rows {
+			copy(data[r*cols:], vec)
+		}
+	default:
+		return errors.Errorf("Unhandled value type %T", data)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package dep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadEmbeddings(t *testing.T) {
+	words := []string{"the", "cat", "sat"}
+	vecs := [][]float64{{1, 2, 3}, {-1, 0.5, 0}, {0.25, -0.125, 8}}
+
+	var text, glove, bin bytes.Buffer
+	fmt.Fprintf(&text, "%d %d\n", len(words), 3)
+	fmt.Fprintf(&bin, "%d %d\n", len(words), 3)
+	for i, w := range words {
+		fmt.Fprintf(&text, "%s %v %v %v\n", w, vecs[i][0], vecs[i][1], vecs[i][2])
+		fmt.Fprintf(&glove, "%s %v %v %v\n", w, vecs[i][0], vecs[i][1], vecs[i][2])
+
+		bin.WriteString(w + " ")
+		for _, f := range vecs[i] {
+			binary.Write(&bin, binary.LittleEndian, math.Float32bits(float32(f)))
+		}
+		bin.WriteString("\n")
+	}
+
+	for _, tc := range []struct {
+		format EmbeddingFormat
+		data   []byte
+	}{
+		{Word2VecText, text.Bytes()},
+		{GloVe, glove.Bytes()},
+		{Word2VecBinary, bin.Bytes()},
+	} {
+		var gotWords []string
+		var gotVecs [][]float64
+		fn := func(word string, vec []float64) error {
+			gotWords = append(gotWords, word)
+			gotVecs = append(gotVecs, vec)
+			return nil
+		}
+		if err := readEmbeddings(bytes.NewReader(tc.data), tc.format, fn); err != nil {
+			t.Errorf("%v: %+v", tc.format, err)
+			continue
+		}
+		assert.Equal(t, words, gotWords, "%v", tc.format)
+		assert.Equal(t, vecs, gotVecs, "%v", tc.format)
+	}
+
+	if err := readEmbeddings(strings.NewReader("the 1 2 x\n"), GloVe, func(string, []float64) error { return nil }); err == nil {
+		t.Error("Expected an error reading a malformed vector")
+	}
+	if err := readEmbeddings(bytes.NewReader(glove.Bytes()), Word2VecText, func(string, []float64) error { return nil }); err == nil {
+		t.Error("Expected an error reading a word2vec file without a header")
+	}
+
+	f, err := ParseEmbeddingFormat("word2vec-binary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Word2VecBinary, f)
+	if _, err = ParseEmbeddingFormat("fasttext"); err == nil {
+		t.Error("Expected an error parsing an unknown format")
+	}
+}
+
+func TestTrainer_PretrainedEmbeddings(t *testing.T) {
+	f, err := ioutil.TempFile("", "embeddings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	fmt.Fprintln(f, "guerrillas 1 1 1 1")  // only in the corpus as "Guerrillas"
+	fmt.Fprintln(f, "threatened 2 2 2 2")  // in the corpus
+	fmt.Fprintln(f, "zyzzyva 0.5 0.5 0 0") // not in the corpus
+	f.Close()
+
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.EmbeddingSize = 4
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts),
+		WithPretrainedEmbeddings(f.Name(), GloVe, AddPretrainedWords(), FreezePretrained()))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	dict := trainer.Corpus()
+	rows := make(map[string]int)
+	for _, w := range []string{"Guerrillas", "threatened", "zyzzyva", "assassinate"} {
+		id, ok := dict.Id(w)
+		if !ok {
+			t.Fatalf("Expected %q to be in the corpus", w)
+		}
+		rows[w] = id
+	}
+	if dict.Size() != trainer.nn.e_w.Shape()[0] {
+		t.Errorf("Expected an embedding for each word in the corpus. %d words, %d embeddings", dict.Size(), trainer.nn.e_w.Shape()[0])
+	}
+
+	row := func(w string) []float64 {
+		data, err := float64s(trainer.nn.e_w.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data[rows[w]*conf.EmbeddingSize : (rows[w]+1)*conf.EmbeddingSize]
+	}
+	assert.Equal(t, []float64{1, 1, 1, 1}, row("Guerrillas"))
+	assert.Equal(t, []float64{2, 2, 2, 2}, row("threatened"))
+	assert.Equal(t, []float64{0.5, 0.5, 0, 0}, row("zyzzyva"))
+	before := row("assassinate")
+
+	if err := trainer.Train(2); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(t, []float64{1, 1, 1, 1}, row("Guerrillas"), "frozen embeddings should not be trained")
+	assert.Equal(t, []float64{2, 2, 2, 2}, row("threatened"), "frozen embeddings should not be trained")
+	assert.NotEqual(t, before, row("assassinate"), "other embeddings should be trained")
+
+	// the wrong number of dimensions
+	conf.EmbeddingSize = 5
+	trainer = NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts), WithPretrainedEmbeddings(f.Name(), GloVe))
+	if err := trainer.Init(); err == nil {
+		t.Error("Expected an error when the pretrained embeddings have the wrong number of dimensions")
+	}
+}
@@ -8,17 +8,31 @@ import (
 
 type componentUnavailable string
 
-func (c componentUnavailable) Error() string     { return fmt.Sprintf("%v unavailable", c) }
+func (c componentUnavailable) Error() string     { return fmt.Sprintf("%v unavailable", string(c)) }
 func (c componentUnavailable) Component() string { return string(c) }
 
-// TarpitError is an error when the arc-standard is stuck.
+// TarpitError is an error when the transition system is stuck, or runs out of its budget of transitions.
 // It implements GoStringer, which when called will output the state as a string.
 // It also implements lingo.Sentencer, so the offending sentence can easily be retrieved
-type TarpitError struct{ *configuration }
-
-func (err TarpitError) Error() string { return "Tarpit Error" }
-
-// NonProjective error is the error that is emitted when the dependency tree is not projective (that is to say the children cross lines)
+//
+// When parsing, the tree is repaired and returned along with the TarpitError.
+type TarpitError struct {
+	*configuration
+
+	Transitions int   // the number of transitions applied before the parser stopped
+	Budget      int   // the most transitions the sentence should need
+	Repaired    []int // the words that were attached to the tree by the repair
+}
+
+func (err TarpitError) Error() string {
+	if err.Budget == 0 {
+		return "Tarpit Error"
+	}
+	return fmt.Sprintf("Tarpit Error: stopped after %d transitions (budget %d). %d words were attached by the repair", err.Transitions, err.Budget, len(err.Repaired))
+}
+
+// NonProjective error is the error that is emitted when the dependency tree is not projective (that is to say the children cross lines),
+// and the transition system can only build projective trees
 type NonProjectiveError struct{ *lingo.Dependency }
 
 func (err NonProjectiveError) Error() string { return "Non-projective tree" }
@@ -1,11 +1,15 @@
 package dep
 
 import (
+	"bytes"
 	"fmt"
-	"io/ioutil"
+	"sort"
+	"text/tabwriter"
+	"unicode"
 
 	"github.com/chewxy/lingo"
 	"github.com/chewxy/lingo/treebank"
+	"github.com/pkg/errors"
 )
 
 // Performance is a tuple that holds performance information from a training session
@@ -15,6 +19,7 @@ type Performance struct {
 	LAS  float64 // Labeled Attachment Score
 	UEM  float64 // Unlabelled Exact Match
 	Root float64 // Correct Roots Ratio
+	POS  float64 // POS Tagging Accuracy
 }
 
 func (p Performance) String() string {
@@ -22,70 +27,456 @@ func (p Performance) String() string {
 UAS: %.5f
 LAS: %.5f
 UEM: %.5f
-ROO: %.5f`
+ROO: %.5f
+POS: %.5f`
 
-	return fmt.Sprintf(s, p.Iter, p.UAS, p.LAS, p.UEM, p.Root)
+	return fmt.Sprintf(s, p.Iter, p.UAS, p.LAS, p.UEM, p.Root, p.POS)
 }
 
 // performance evaluation related code goes here
 
-// Evaluate compares predicted trees with the gold standard trees and returns a Performance. It panics if the number of predicted trees and the number of gold trees aren't the same
-func Evaluate(predictedTrees, goldTrees []*lingo.Dependency) Performance {
-	if len(predictedTrees) != len(goldTrees) {
-		panic(fmt.Sprintf("%d predicted trees; %d gold trees. Unable to compare", len(predictedTrees), len(goldTrees)))
+// Evaluate compares predicted trees with the gold standard trees and returns a Performance, which includes the POS tagging accuracy of models that tag jointly.
+// All the words are scored, including punctuation. It returns an error if the number of predicted trees and the number of gold trees aren't the same.
+// See EvaluateDetailed for a more detailed evaluation.
+func Evaluate(predictedTrees, goldTrees []*lingo.Dependency) (Performance, error) {
+	e, err := EvaluateDetailed(predictedTrees, goldTrees, WithPunctuation())
+	if err != nil {
+		return Performance{}, err
 	}
+	return e.Performance(), nil
+}
+
+// EvalOpt is an option for EvaluateDetailed
+type EvalOpt func(*Evaluation)
 
-	var correctLabels, correctHeads, correctTrees, correctRoot, sumArcs float64
-	var check int
+// WithPunctuation includes punctuation in the scores. By default, words that consist only of punctuation are excluded, as in CoNLL-X.
+func WithPunctuation() EvalOpt {
+	f := func(e *Evaluation) {
+		e.Punctuation = true
+	}
+	return f
+}
 
-	for i, tr := range predictedTrees {
-		gTr := goldTrees[i]
+// Metrics holds the precision, recall and F1 of one kind of arc
+type Metrics struct {
+	Gold      int     `json:"gold"`      // number of such arcs in the gold standard
+	Predicted int     `json:"predicted"` // number of such arcs the parser predicted
+	Correct   int     `json:"correct"`   // number of predicted arcs that are correct
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
 
-		if len(tr.AnnotatedSentence) != len(gTr.AnnotatedSentence) {
-			sumArcs += float64(gTr.N())
+func (m *Metrics) finish() {
+	m.Precision = ratio(m.Correct, m.Predicted)
+	m.Recall = ratio(m.Correct, m.Gold)
+	if m.Precision+m.Recall > 0 {
+		m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+	}
+}
 
-			// log.Printf("WARNING: %q and %q do not have the same length", tr, gTr)
-			continue
+// Accuracy holds the attachment scores of a group of words
+type Accuracy struct {
+	Words         int     `json:"words"`
+	CorrectHeads  int     `json:"correctHeads"`
+	CorrectLabels int     `json:"correctLabels"` // words with the correct head and label
+	UAS           float64 `json:"uas"`
+	LAS           float64 `json:"las"`
+}
+
+func (a *Accuracy) add(head, label bool) {
+	a.Words++
+	if head {
+		a.CorrectHeads++
+		if label {
+			a.CorrectLabels++
 		}
+	}
+}
+
+func (a *Accuracy) finish() {
+	a.UAS = ratio(a.CorrectHeads, a.Words)
+	a.LAS = ratio(a.CorrectLabels, a.Words)
+}
+
+// Evaluation is a detailed evaluation of a parser against a gold standard.
+// It can be printed as text, or marshalled into JSON with encoding/json.
+//
+// A word is attached correctly when its head is correct, and labelled correctly when both its head and its label are correct.
+type Evaluation struct {
+	Punctuation bool `json:"punctuation"` // whether punctuation is scored
 
-		var nCorrectHead int
-		for j, a := range tr.AnnotatedSentence[1:] {
-			b := gTr.AnnotatedSentence[j+1]
-			if a.HeadID() == b.HeadID() {
-				correctHeads++
-				nCorrectHead++
+	Accuracy
+	Root float64 `json:"root"` // ratio of sentences with the correct root
+	UEM  float64 `json:"uem"`  // Unlabelled Exact Match: ratio of sentences with every word attached correctly
+	LEM  float64 `json:"lem"`  // Labelled Exact Match: ratio of sentences with every word labelled correctly
+	POS  float64 `json:"pos"`  // POS tagging accuracy, for models that tag jointly
+
+	// CoNLL 2018 metrics. They only score content words: words that aren't punctuation and aren't attached by a functional relation (see lingo.IsFunctional).
+	CLAS Metrics `json:"clas"` // content words labelled correctly
+	MLAS Metrics `json:"mlas"` // as CLAS, also with the correct POS tag, and the functional words attached to them labelled and tagged correctly. lingo has no morphological features, so features aren't compared.
+	BLEX Metrics `json:"blex"` // as CLAS, also with the correct lemma. Words without lemmas are compared by their values.
+
+	Sentences        int `json:"sentences"`
+	Tokens           int `json:"tokens"`           // all the words, including the ones that aren't scored
+	DifferentLengths int `json:"differentLengths"` // sentences where the predicted sentence and the gold sentence have different lengths. All their words count as wrong.
+
+	Labels    map[string]Metrics        `json:"labels"`    // keyed by relation. Counted as labelled correctly.
+	Confusion map[string]map[string]int `json:"confusion"` // gold relation → predicted relation → count, of the words attached correctly
+
+	Lengths   map[int]Accuracy `json:"lengths"`   // keyed by the lower bound of the sentence length bin: 1-10, 11-20, ..., 41 and more
+	Distances map[int]Metrics  `json:"distances"` // keyed by the distance between a word and its head: 0 for the root, then 1, 2, ..., 7 for 7 and more. Counted as attached correctly.
+}
+
+func isPunctuation(a *lingo.Annotation) bool {
+	return a.Value != "" && lingo.StringIs(a.Value, unicode.IsPunct)
+}
+
+func isContent(a *lingo.Annotation) bool {
+	return a.DependencyType != lingo.Punct && !lingo.IsFunctional(a.DependencyType) && !isPunctuation(a)
+}
+
+// lengthBin returns the lower bound of the bin of sentences of length n
+func lengthBin(n int) int {
+	if n > 40 {
+		return 41
+	}
+	return (n-1)/10*10 + 1
+}
+
+// distanceBin returns the bin of an arc from head to dependent
+func distanceBin(head, dependent int) int {
+	if head == 0 {
+		return 0
+	}
+	d := head - dependent
+	if d < 0 {
+		d = -d
+	}
+	if d > 7 {
+		return 7
+	}
+	return d
+}
+
+// EvaluateDetailed compares the predicted trees with the gold standard trees. Unlike Evaluate, it breaks the scores down by relation,
+// sentence length and dependency distance, and scores CoNLL 2018 metrics.
+//
+// It returns an error if the number of predicted trees and the number of gold trees aren't the same.
+func EvaluateDetailed(predicted, gold []*lingo.Dependency, opts ...EvalOpt) (Evaluation, error) {
+	if len(predicted) != len(gold) {
+		return Evaluation{}, errors.Errorf("%d predicted trees; %d gold trees. Unable to compare", len(predicted), len(gold))
+	}
+
+	e := Evaluation{
+		Sentences: len(gold),
+		Labels:    make(map[string]Metrics),
+		Confusion: make(map[string]map[string]int),
+		Lengths:   make(map[int]Accuracy),
+		Distances: make(map[int]Metrics),
+	}
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	var correctRoots, exactHeads, exactLabels, correctTags int
+	for i, g := range gold {
+		if g == nil {
+			return Evaluation{}, errors.Errorf("Gold tree %d is nil", i)
+		}
+		p := predicted[i]
+		gs := g.AnnotatedSentence
+		sameLength := p != nil && len(p.AnnotatedSentence) == len(gs)
+		if !sameLength {
+			e.DifferentLengths++
+		}
+
+		length := lengthBin(len(gs) - 1)
+		sentence := e.Lengths[length]
+		exactHead, exactLabel := sameLength, sameLength
+
+		for j := 1; j < len(gs); j++ {
+			ga := gs[j]
+			e.Tokens++
+			if !e.Punctuation && isPunctuation(ga) {
+				continue
 			}
+			gh := g.Head(j)
 
-			if a.DependencyType == b.DependencyType {
-				correctLabels++
+			var pa *lingo.Annotation
+			var ph int
+			if sameLength {
+				pa = p.AnnotatedSentence[j]
+				ph = p.Head(j)
 			}
-			sumArcs++
+			head := pa != nil && ph == gh
+			label := head && pa.DependencyType == ga.DependencyType
+			if pa != nil && pa.POSTag == ga.POSTag {
+				correctTags++
+			}
+
+			e.Accuracy.add(head, label)
+			sentence.add(head, label)
+			exactHead = exactHead && head
+			exactLabel = exactLabel && label
+
+			// labels
+			gl := ga.DependencyType.String()
+			lm := e.Labels[gl]
+			lm.Gold++
+			if label {
+				lm.Correct++
+			}
+			e.Labels[gl] = lm
+
+			// distances
+			gd := distanceBin(gh, j)
+			dm := e.Distances[gd]
+			dm.Gold++
+			if head {
+				dm.Correct++
+			}
+			e.Distances[gd] = dm
+
+			if pa == nil {
+				continue
+			}
+			pl := pa.DependencyType.String()
+			lm = e.Labels[pl]
+			lm.Predicted++
+			e.Labels[pl] = lm
+
+			if ph >= 0 {
+				pd := distanceBin(ph, j)
+				dm = e.Distances[pd]
+				dm.Predicted++
+				e.Distances[pd] = dm
+			}
+
+			if head {
+				if _, ok := e.Confusion[gl]; !ok {
+					e.Confusion[gl] = make(map[string]int)
+				}
+				e.Confusion[gl][pl]++
+			}
+		}
+		e.Lengths[length] = sentence
+
+		if exactHead {
+			exactHeads++
 		}
-		if nCorrectHead == gTr.N() {
-			correctTrees++
+		if exactLabel {
+			exactLabels++
 		}
-		if tr.Root() == gTr.Root() {
-			correctRoot++
+		if sameLength && p.Root() == g.Root() {
+			correctRoots++
 		}
 
-		// check 5 per iteration
-		if check < 5 {
-			logf("predictedHeads: \n%v\n%v\n", tr.Heads(), gTr.Heads())
-			logf("Ns: %v | %v || Correct: %v", tr.N(), gTr.N(), nCorrectHead)
-			check++
+		e.scoreContent(p, g, sameLength)
+	}
+
+	e.Accuracy.finish()
+	e.Root = ratio(correctRoots, e.Sentences)
+	e.UEM = ratio(exactHeads, e.Sentences)
+	e.LEM = ratio(exactLabels, e.Sentences)
+	e.POS = ratio(correctTags, e.Accuracy.Words)
+	e.CLAS.finish()
+	e.MLAS.finish()
+	e.BLEX.finish()
+	for k, m := range e.Labels {
+		m.finish()
+		e.Labels[k] = m
+	}
+	for k, m := range e.Distances {
+		m.finish()
+		e.Distances[k] = m
+	}
+	for k, a := range e.Lengths {
+		a.finish()
+		e.Lengths[k] = a
+	}
+	return e, nil
+}
+
+// scoreContent counts the content words of a pair of trees for CLAS, MLAS and BLEX
+func (e *Evaluation) scoreContent(p, g *lingo.Dependency, sameLength bool) {
+	gs := g.AnnotatedSentence
+	for j := 1; j < len(gs); j++ {
+		if isContent(gs[j]) {
+			e.CLAS.Gold++
+			e.MLAS.Gold++
+			e.BLEX.Gold++
+		}
+	}
+	if p == nil {
+		return
+	}
+
+	ps := p.AnnotatedSentence
+	for j := 1; j < len(ps); j++ {
+		pa := ps[j]
+		if !isContent(pa) {
+			continue
+		}
+		e.CLAS.Predicted++
+		e.MLAS.Predicted++
+		e.BLEX.Predicted++
+
+		if !sameLength {
+			continue
+		}
+		ga := gs[j]
+		if !isContent(ga) || p.Head(j) != g.Head(j) || pa.DependencyType != ga.DependencyType {
+			continue
+		}
+		e.CLAS.Correct++
+		if lemma(pa) == lemma(ga) {
+			e.BLEX.Correct++
+		}
+		if pa.POSTag == ga.POSTag && sameFunctionWords(p, g, j) {
+			e.MLAS.Correct++
+		}
+	}
+}
+
+// sameFunctionWords checks if the same function words, with the same relations and POS tags, are attached to the jth word of both trees
+func sameFunctionWords(p, g *lingo.Dependency, j int) bool {
+	for k := 1; k < len(g.AnnotatedSentence); k++ {
+		pa, ga := p.AnnotatedSentence[k], g.AnnotatedSentence[k]
+		pf := p.Head(k) == j && lingo.IsFunctional(pa.DependencyType)
+		gf := g.Head(k) == j && lingo.IsFunctional(ga.DependencyType)
+		if pf != gf {
+			return false
+		}
+		if gf && (pa.DependencyType != ga.DependencyType || pa.POSTag != ga.POSTag) {
+			return false
+		}
+	}
+	return true
+}
+
+func lemma(a *lingo.Annotation) string {
+	if a.Lemma != "" {
+		return a.Lemma
+	}
+	return a.Value
+}
+
+// Performance returns the scores of the evaluation that a Performance holds
+func (e Evaluation) Performance() Performance {
+	return Performance{UAS: e.UAS, LAS: e.LAS, UEM: e.UEM, Root: e.Root, POS: e.POS}
+}
+
+func (e Evaluation) String() string {
+	var buf bytes.Buffer
+	punct := "excluded"
+	if e.Punctuation {
+		punct = "included"
+	}
+	fmt.Fprintf(&buf, "Punctuation      : %s\n", punct)
+	fmt.Fprintf(&buf, "UAS              : %d/%d = %.5f\n", e.CorrectHeads, e.Accuracy.Words, e.UAS)
+	fmt.Fprintf(&buf, "LAS              : %d/%d = %.5f\n", e.CorrectLabels, e.Accuracy.Words, e.LAS)
+	fmt.Fprintf(&buf, "UEM              : %.5f\n", e.UEM)
+	fmt.Fprintf(&buf, "LEM              : %.5f\n", e.LEM)
+	fmt.Fprintf(&buf, "Root             : %.5f\n", e.Root)
+	fmt.Fprintf(&buf, "POS              : %.5f\n", e.POS)
+	fmt.Fprintf(&buf, "Different Lengths: %d/%d\n\n", e.DifferentLengths, e.Sentences)
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Metric\tGold\tPredicted\tCorrect\tPrecision\tRecall\tF1\t")
+	for _, m := range []struct {
+		name string
+		Metrics
+	}{{"CLAS", e.CLAS}, {"MLAS", e.MLAS}, {"BLEX", e.BLEX}} {
+		writeMetrics(w, m.name, m.Metrics)
+	}
+	w.Flush()
+
+	labels := make([]string, 0, len(e.Labels))
+	for l := range e.Labels {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	buf.WriteString("\n")
+	w = tabwriter.NewWriter(&buf, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Relation\tGold\tPredicted\tCorrect\tPrecision\tRecall\tF1\t")
+	for _, l := range labels {
+		writeMetrics(w, l, e.Labels[l])
+	}
+	w.Flush()
+
+	buf.WriteString("\n")
+	w = tabwriter.NewWriter(&buf, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Distance\tGold\tPredicted\tCorrect\tPrecision\tRecall\tF1\t")
+	for d := 0; d <= 7; d++ {
+		m, ok := e.Distances[d]
+		if !ok {
+			continue
 		}
+		name := fmt.Sprintf("%d", d)
+		switch d {
+		case 0:
+			name = "root"
+		case 7:
+			name = "7+"
+		}
+		writeMetrics(w, name, m)
 	}
+	w.Flush()
 
-	uas := correctHeads / sumArcs
-	las := correctLabels / sumArcs
-	uem := correctTrees / float64(len(predictedTrees))
-	roo := correctRoot / float64(len(predictedTrees))
+	buf.WriteString("\n")
+	w = tabwriter.NewWriter(&buf, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Length\tWords\tUAS\tLAS\t")
+	for l := 1; l <= 41; l += 10 {
+		a, ok := e.Lengths[l]
+		if !ok {
+			continue
+		}
+		name := fmt.Sprintf("%d-%d", l, l+9)
+		if l == 41 {
+			name = "41+"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.5f\t%.5f\t\n", name, a.Words, a.UAS, a.LAS)
+	}
+	w.Flush()
 
-	return Performance{UAS: uas, LAS: las, UEM: uem, Root: roo}
+	// confusion matrix: rows are the gold relations, columns are the predicted relations
+	buf.WriteString("\nConfusion Matrix of correctly attached words (rows: gold, columns: predicted)\n")
+	w = tabwriter.NewWriter(&buf, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "\t")
+	for _, l := range labels {
+		fmt.Fprintf(w, "%s\t", l)
+	}
+	fmt.Fprintln(w)
+	for _, g := range labels {
+		fmt.Fprintf(w, "%s\t", g)
+		for _, p := range labels {
+			fmt.Fprintf(w, "%d\t", e.Confusion[g][p])
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+func writeMetrics(w *tabwriter.Writer, name string, m Metrics) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.5f\t%.5f\t%.5f\t\n", name, m.Gold, m.Predicted, m.Correct, m.Precision, m.Recall, m.F1)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
 }
 
-func (t *Trainer) crossValidate(st []treebank.SentenceTag) Performance {
-	preds := t.predMany(st)
+func (t *Trainer) crossValidate(st []treebank.SentenceTag) (Performance, error) {
+	preds, err := t.predMany(st)
+	if err != nil {
+		return Performance{}, err
+	}
 	golds := make([]*lingo.Dependency, len(st))
 
 	for i, s := range st {
@@ -94,22 +485,18 @@ func (t *Trainer) crossValidate(st []treebank.SentenceTag) Performance {
 	return Evaluate(preds, golds)
 }
 
-func (t *Trainer) predMany(sentenceTags []treebank.SentenceTag) []*lingo.Dependency {
-	retVal := make([]*lingo.Dependency, len(sentenceTags))
+// predMany parses the sentences with the model being trained. The sentences that couldn't be parsed to the end are evaluated as repaired.
+func (t *Trainer) predMany(sentenceTags []treebank.SentenceTag) ([]*lingo.Dependency, error) {
+	sentences := make([]lingo.AnnotatedSentence, len(sentenceTags))
 	for i, st := range sentenceTags {
-		dep, err := t.pred(st.AnnotatedSentence(t))
-		if err != nil {
-			ioutil.WriteFile("fullGraph.dot", []byte(t.nn.g.ToDot()), 0644)
-			panic(fmt.Sprintf("%+v", err))
-		}
-		retVal[i] = dep
+		sentences[i] = st.AnnotatedSentence(t)
 	}
-	return retVal
-}
 
-func (t *Trainer) pred(as lingo.AnnotatedSentence) (*lingo.Dependency, error) {
 	d := new(Parser)
 	d.Model = t.Model
-
-	return d.predict(as)
+	retVal, err := d.ParseBatch(sentences)
+	if _, ok := err.(TarpitError); ok {
+		return retVal, nil
+	}
+	return retVal, err
 }
@@ -0,0 +1,80 @@
+package dep
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateDetailed(t *testing.T) {
+	assert := assert.New(t)
+
+	// Yet we did n't charge them for the evacuation .
+	st := simpleSentence()[0]
+	gold := []*lingo.Dependency{st.Dependency(dummyFix{})}
+	pred := st.Dependency(dummyFix{})
+	pred.Annotation(6).DependencyType = lingo.IObj // "them": correct head, wrong label
+	pred.Annotation(8).SetHead(pred.Annotation(5)) // "the": wrong head
+	predicted := []*lingo.Dependency{pred}
+
+	e, err := EvaluateDetailed(predicted, gold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(10, e.Tokens)
+	assert.Equal(9, e.Accuracy.Words, "punctuation is excluded")
+	assert.Equal(8, e.CorrectHeads)
+	assert.Equal(7, e.CorrectLabels)
+	assert.Equal(8.0/9.0, e.UAS)
+	assert.Equal(7.0/9.0, e.LAS)
+	assert.Equal(0.0, e.UEM)
+	assert.Equal(1.0, e.Root)
+
+	// the content words are "we", "n't", "charge", "them" and "evacuation"
+	assert.Equal(5, e.CLAS.Gold)
+	assert.Equal(5, e.CLAS.Predicted)
+	assert.Equal(4, e.CLAS.Correct)
+	assert.InDelta(0.8, e.CLAS.F1, 1e-12)
+	assert.Equal(4, e.BLEX.Correct)
+	// "charge" and "evacuation" have the wrong function words attached
+	assert.Equal(2, e.MLAS.Correct)
+
+	assert.Equal(Metrics{Gold: 1, Predicted: 0, Correct: 0}, e.Labels[lingo.DObj.String()])
+	assert.Equal(1, e.Labels[lingo.IObj.String()].Predicted)
+	assert.Equal(1, e.Confusion[lingo.DObj.String()][lingo.IObj.String()])
+	assert.Equal(1, e.Distances[0].Correct)
+	assert.Equal(1, e.Distances[3].Predicted-e.Distances[3].Gold)
+	assert.Equal(9, e.Lengths[1].Words)
+
+	e, err = EvaluateDetailed(predicted, gold, WithPunctuation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(10, e.Accuracy.Words)
+	assert.Equal(0.9, e.UAS)
+	assert.Equal(e.UAS, e.Performance().UAS)
+
+	if _, err = json.Marshal(e); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(e.String(), "CLAS") {
+		t.Errorf("Expected the CoNLL 2018 metrics to be printed. Got \n%v", e)
+	}
+
+	// sentences of different lengths count as wrong
+	gold = append(gold, mediumSentence()[0].Dependency(dummyFix{}))
+	predicted = append(predicted, gold[0])
+	e, err = EvaluateDetailed(predicted, gold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(1, e.DifferentLengths)
+	assert.Equal(8, e.CorrectHeads)
+
+	if _, err = EvaluateDetailed(predicted[:1], gold); err == nil {
+		t.Error("Expected an error when the number of trees differ")
+	}
+}
@@ -14,14 +14,16 @@ type example struct {
 
 	features []int // features are used in the embeddings
 	labels   []int // labels are used in scoring the transitions
+
+	id int // the position of the example in the examples made from the training set, so the order of the examples can be checkpointed
 }
 
-func makeExamples(sentenceTags []treebank.SentenceTag, conf NNConfig, dict *corpus.Corpus, ts []transition, f lingo.AnnotationFixer) []example {
+func makeExamples(sentenceTags []treebank.SentenceTag, conf NNConfig, fs *featureSet, dict *corpus.Corpus, ts []transition, f lingo.AnnotationFixer) []example {
 	var examples []example
 
 	var tarpit, nonprojective, good int
 	for i, sentenceTag := range sentenceTags {
-		exs, err := makeOneExample(i, sentenceTag, dict, ts, f)
+		exs, err := makeOneExample(i, sentenceTag, conf.TransitionSystem, conf.JointTagging, fs, dict, ts, f)
 		if err != nil {
 			switch err.(type) {
 			case TarpitError:
@@ -35,29 +37,33 @@ func makeExamples(sentenceTags []treebank.SentenceTag, conf NNConfig, dict *corp
 		}
 	}
 
+	for i := range examples {
+		examples[i].id = i
+	}
+
 	logf("Number of SentenceTags Generated Into Examples: %d/%d | Number of Examples: %d | Number of nonprojective examples: %d | Number of tarpit examples: %d", good, len(sentenceTags), len(examples), nonprojective, tarpit)
 	return examples
 }
 
 // makeOneExample is an example of a poorly named function. It makes an example from a SentenceTag
-func makeOneExample(i int, sentenceTag treebank.SentenceTag, dict *corpus.Corpus, ts []transition, f lingo.AnnotationFixer) ([]example, error) {
+func makeOneExample(i int, sentenceTag treebank.SentenceTag, system TransitionSystem, joint bool, fs *featureSet, dict *corpus.Corpus, ts []transition, f lingo.AnnotationFixer) ([]example, error) {
 	var examples []example
 
 	s := sentenceTag.AnnotatedSentence(f)
 	dep := s.Dependency()
-	if dep.IsProjective() {
+	if !system.projective() || dep.IsProjective() {
 		c := newConfiguration(s, true)
+		c.setSystem(system, joint)
 
-		count := 0
-		for !c.isTerminal() && count < 1000 {
-			if count == 999 {
-				return examples, TarpitError{c}
+		for count := 0; !c.isTerminal(); count++ {
+			if count == c.budget() {
+				return examples, c.tarpit(count)
 			}
 
 			oracle := c.oracle(dep)
-			features := getFeatures(c, dict)
+			features := fs.extract(c, dict)
 
-			labels := make([]int, MAXTRANSITION)
+			labels := make([]int, len(ts))
 			for i, t := range ts {
 				if t == oracle {
 					labels[i] = 1
@@ -68,11 +74,10 @@ func makeOneExample(i int, sentenceTag treebank.SentenceTag, dict *corpus.Corpus
 				}
 			}
 
-			ex := example{transition{oracle.Move, oracle.DependencyType}, features, labels}
+			ex := example{transition: oracle, features: features, labels: labels}
 			examples = append(examples, ex)
 
 			c.apply(oracle)
-			count++
 		}
 	} else {
 		return nil, NonProjectiveError{dep}
@@ -81,9 +86,9 @@ func makeOneExample(i int, sentenceTag treebank.SentenceTag, dict *corpus.Corpus
 	return examples, nil
 }
 
-func shuffleExamples(a []example) {
+func shuffleExamples(a []example, r *rand.Rand) {
 	for i := range a {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		a[i], a[j] = a[j], a[i]
 	}
 }
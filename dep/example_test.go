@@ -10,7 +10,7 @@ func TestMakeExamples(t *testing.T) {
 	st := simpleSentence()
 	dict := corpus.GenerateCorpus(st)
 
-	exs := makeExamples(st, DefaultNNConfig, dict, transitions, dummyFix{})
+	exs := makeExamples(st, DefaultNNConfig, defaultFeatures, dict, transitions, dummyFix{})
 	if len(exs) != 20 {
 		t.Error("Expected 20 examples to be generated from simple sentence")
 	}
@@ -6,12 +6,11 @@ import (
 	"github.com/chewxy/lingo"
 )
 
-// applies common fixes
-func fix(d *lingo.Dependency) {
-	// NNP fix:
-	// If a sentence is [a, b, c, D, E, f, g]
-	// where D, E are NNPs, they should be compound words
-	// The head should be the one with higher headID
+// fixProperNounCompounds is the NNP fix:
+// If a sentence is [a, b, c, D, E, f, g]
+// where D, E are NNPs, they should be compound words
+// The head should be the one with higher headID
+func fixProperNounCompounds(d *lingo.Dependency) {
 	spans := properNounSpans(d)
 	for _, s := range spans {
 		// we don't care about single word proper nouns
@@ -91,8 +90,6 @@ func fix(d *lingo.Dependency) {
 		logf("More than zero compound roots not handled yet")
 
 	}
-
-	// Number fix
 }
 
 func properNounSpans(d *lingo.Dependency) (retVal []span) {
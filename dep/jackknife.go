@@ -0,0 +1,83 @@
+package dep
+
+import (
+	"math/rand"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/pos"
+	"github.com/chewxy/lingo/treebank"
+	"github.com/pkg/errors"
+)
+
+// jackknife holds the settings for replacing the gold POS tags of the training set with tags predicted by a pos.Tagger
+type jackknife struct {
+	folds      int
+	iterations int
+	opts       []pos.ConsOpt
+}
+
+// WithJackknifing sets up a *Trainer to train on POS tags predicted by a pos.Tagger instead of the gold tags, so the parser
+// sees the same kind of (sometimes wrong) tags in training that it sees when parsing.
+//
+// The training set is split into the given number of folds. The sentences of each fold are tagged by a pos.Tagger that is
+// trained for the given number of iterations on the other folds. The taggers are created with the options passed in, so they
+// can be given a lemmatizer, stemmer, clusters, config or feature templates. WithModel should not be passed in, as every fold
+// needs a fresh model.
+//
+// The tags are predicted once, when the Trainer is initialized. The taggers are seeded by the Trainer. Jackknifing cannot be used with JointTagging.
+func WithJackknifing(folds, iterations int, opts ...pos.ConsOpt) TrainerConsOpt {
+	f := func(t *Trainer) {
+		t.jackknife = &jackknife{
+			folds:      folds,
+			iterations: iterations,
+			opts:       opts,
+		}
+	}
+	return f
+}
+
+// tag returns a copy of the sentences, with the gold POS tags replaced by the tags predicted by taggers trained on the other folds.
+// The ith sentence is in fold i % folds. The tagger of each fold is seeded with a number drawn from r.
+func (j *jackknife) tag(sentences []treebank.SentenceTag, r *rand.Rand) ([]treebank.SentenceTag, error) {
+	if j.folds < 2 {
+		return nil, errors.Errorf("Jackknifing needs at least 2 folds. Got %d", j.folds)
+	}
+	if j.folds > len(sentences) {
+		return nil, errors.Errorf("Cannot split %d sentences into %d folds", len(sentences), j.folds)
+	}
+
+	retVal := make([]treebank.SentenceTag, len(sentences))
+	for fold := 0; fold < j.folds; fold++ {
+		var rest []treebank.SentenceTag // pos.Tagger.Train shuffles the sentences, so this has to be a fresh slice
+		for i, st := range sentences {
+			if i%j.folds != fold {
+				rest = append(rest, st)
+			}
+		}
+
+		tagger := pos.New(append(j.opts[:len(j.opts):len(j.opts)], pos.WithSeed(r.Int63()))...)
+		if err := tagger.Train(rest, j.iterations); err != nil {
+			return nil, errors.Wrapf(err, "Unable to train the tagger of fold %d", fold)
+		}
+
+		var correct, count int
+		for i := fold; i < len(sentences); i += j.folds {
+			st := sentences[i]
+			s := st.AnnotatedSentence(tagger)
+			tagger.Tag(s)
+
+			tags := make([]lingo.POSTag, len(st.Tags))
+			for k, a := range s[1:] {
+				tags[k] = a.POSTag
+				if tags[k] == st.Tags[k] {
+					correct++
+				}
+				count++
+			}
+			st.Tags = tags
+			retVal[i] = st
+		}
+		logf("Jackknifing fold %d: %d/%d tags correct", fold, correct, count)
+	}
+	return retVal, nil
+}
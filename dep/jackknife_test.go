@@ -0,0 +1,74 @@
+package dep
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/chewxy/lingo/pos"
+	"github.com/chewxy/lingo/treebank"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJackknife(t *testing.T) {
+	sts := append(allSentences(), cvSentences()...)
+	gold := make([]treebank.SentenceTag, len(sts))
+	for i, st := range sts {
+		gold[i] = st
+		gold[i].Tags = append(gold[i].Tags[:0:0], st.Tags...)
+	}
+
+	j := &jackknife{folds: 3, iterations: 5}
+	tagged, err := j.tag(sts, rand.New(rand.NewSource(1337)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var differ int
+	for i, st := range tagged {
+		assert.Equal(t, gold[i].Tags, sts[i].Tags, "the training set should not be modified")
+		assert.Equal(t, gold[i].Sentence, st.Sentence)
+		assert.Equal(t, gold[i].Heads, st.Heads)
+		assert.Equal(t, gold[i].Labels, st.Labels)
+		assert.Equal(t, len(gold[i].Tags), len(st.Tags))
+		for k := range st.Tags {
+			if st.Tags[k] != gold[i].Tags[k] {
+				differ++
+			}
+		}
+	}
+	t.Logf("%d tags differ from the gold tags", differ)
+
+	if _, err = (&jackknife{folds: 1}).tag(sts, nil); err == nil {
+		t.Error("Expected an error with a single fold")
+	}
+	if _, err = (&jackknife{folds: len(sts) + 1}).tag(sts, nil); err == nil {
+		t.Error("Expected an error with more folds than sentences")
+	}
+
+	j.opts = []pos.ConsOpt{pos.WithFeatureTemplates(make([]pos.FeatureTemplate, pos.MAXTEMPLATES+1)...)}
+	if _, err = j.tag(sts, rand.New(rand.NewSource(1337))); err == nil {
+		t.Error("Expected an error when the taggers cannot be trained")
+	}
+}
+
+func TestTrainer_Jackknifing(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts), WithJackknifing(2, 5))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if &trainer.trainingSet[0] == &sts[0] {
+		t.Error("Expected the training set to be replaced by a tagged copy")
+	}
+	if err := trainer.Train(2); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	trainer = NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts), WithJackknifing(len(sts)+1, 5))
+	if err := trainer.Init(); err == nil {
+		t.Error("Expected an error when there are more folds than sentences")
+	}
+}
@@ -0,0 +1,50 @@
+package dep
+
+import "github.com/chewxy/lingo"
+
+// jointTransitions replaces the Shift transition in the table with one Shift for each POSTag that a word can be tagged with
+func jointTransitions(table []transition) []transition {
+	ts := make([]transition, 0, len(table)+int(lingo.MAXTAG))
+	for _, t := range table {
+		if t.Move != Shift {
+			ts = append(ts, t)
+			continue
+		}
+		for tag := lingo.POSTag(0); tag < lingo.MAXTAG; tag++ {
+			if taggable(tag) {
+				ts = append(ts, transition{Shift, lingo.NoDepType, tag})
+			}
+		}
+	}
+	return ts
+}
+
+// taggable returns true if a word may be tagged with the POSTag when tagging jointly.
+// lingo.X marks the words that haven't been tagged yet, and only the root is tagged with lingo.ROOT_TAG.
+func taggable(tag lingo.POSTag) bool { return tag != lingo.X && tag != lingo.ROOT_TAG }
+
+// canTag checks if the front of the buffer can be shifted with the given POSTag.
+// When not tagging jointly, Shift never carries a POSTag. Otherwise a word keeps the POSTag it was first shifted with (ArcSwap may shift a word more than once).
+func (c *configuration) canTag(tag lingo.POSTag) bool {
+	if !c.joint {
+		return tag == lingo.X
+	}
+	if !taggable(tag) {
+		return false
+	}
+
+	b0 := c.bufferValue(0)
+	if b0 <= 0 {
+		return false
+	}
+	current := c.Annotation(int(b0)).POSTag
+	return current == lingo.X || current == tag
+}
+
+// goldTag is the POSTag the oracle shifts a word with. Gold words that are not taggable are treated as unknown
+func goldTag(tag lingo.POSTag) lingo.POSTag {
+	if taggable(tag) {
+		return tag
+	}
+	return lingo.UNKNOWN_TAG
+}
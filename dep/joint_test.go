@@ -0,0 +1,130 @@
+package dep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJointOracle(t *testing.T) {
+	sts := treebank.ReadConllu(strings.NewReader(nonprojective))
+	sts = append(sts, allSentences()...)
+
+	for _, system := range []TransitionSystem{ArcStandard, ArcSwap} {
+		ts := jointTransitions(system.transitions())
+		for i, st := range sts {
+			s := st.AnnotatedSentence(dummyFix{})
+			d := s.Dependency()
+			if system.projective() && !d.IsProjective() {
+				continue
+			}
+
+			c := newConfiguration(s, true)
+			c.setSystem(system, true)
+			for j := 1; j < c.WordCount(); j++ {
+				if c.Annotation(j).POSTag != lingo.X {
+					t.Fatalf("%v: expected the tags of sentence %d to be cleared", system, i)
+				}
+			}
+			if c.canApply(transition{Shift, lingo.NoDepType, lingo.X}) {
+				t.Errorf("%v: Shift without a POSTag should not be applicable when tagging jointly", system)
+			}
+
+			for count := 0; !c.isTerminal() && count < 1000; count++ {
+				oracle := c.oracle(d)
+				if !c.canApply(oracle) {
+					t.Fatalf("%v: cannot apply %v to %v", system, oracle, c)
+				}
+				lookupTransition(oracle, ts) // panics if the oracle isn't in the table
+				c.apply(oracle)
+			}
+
+			assert.Equal(t, d.Heads(), c.Heads())
+			assert.Equal(t, d.Labels(), c.Labels())
+			for j := 1; j < c.WordCount(); j++ {
+				if c.Annotation(j).POSTag != goldTag(d.Annotation(j).POSTag) {
+					t.Errorf("%v: sentence %d word %d: expected %v. Got %v", system, i, j, d.Annotation(j).POSTag, c.Annotation(j).POSTag)
+				}
+			}
+		}
+	}
+}
+
+func TestTrainer_JointTagging(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.Dropout = 0
+	conf.JointTagging = true
+
+	conf.TransitionSystem = ArcEager
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := trainer.Train(1); err == nil {
+		t.Error("Expected an error when tagging jointly with the arc-eager system")
+	}
+
+	conf.TransitionSystem = ArcStandard
+	trainer = NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(trainer.ts) != len(jointTransitions(ArcStandard.transitions())) {
+		t.Errorf("Expected the trainer to use the joint transitions. Got %d transitions", len(trainer.ts))
+	}
+	if err := trainer.Train(10); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	d := New(trainer.Model)
+	dep, err := d.Parse(sts[0].AnnotatedSentence(dummyFix{}))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for i := 1; i < dep.WordCount(); i++ {
+		if !taggable(dep.Annotation(i).POSTag) {
+			t.Errorf("Expected word %d to be tagged. Got %v", i, dep.Annotation(i).POSTag)
+		}
+	}
+
+	// the option survives a round trip
+	b, err := conf.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf2 NNConfig
+	if err = conf2.GobDecode(b); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, conf2.JointTagging)
+}
+
+func TestEvaluate_POS(t *testing.T) {
+	sts := allSentences()
+	preds := make([]*lingo.Dependency, len(sts))
+	golds := make([]*lingo.Dependency, len(sts))
+	var words float64
+	for i, st := range sts {
+		preds[i] = st.Dependency(dummyFix{})
+		golds[i] = st.Dependency(dummyFix{})
+		words += float64(golds[i].N())
+	}
+
+	perf, err := Evaluate(preds, golds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1.0, perf.POS)
+
+	preds[0].Annotation(1).POSTag = lingo.UNKNOWN_TAG
+	if perf, err = Evaluate(preds, golds); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, (words-1)/words, perf.POS)
+	assert.Equal(t, 1.0, perf.UAS)
+}
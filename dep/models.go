@@ -22,18 +22,29 @@ type Model struct {
 
 func (m *Model) Corpus() *corpus.Corpus { return m.corpus }
 
+// WordEmbeddings returns a copy of the word embeddings. It returns nil if no feature uses words.
 func (m *Model) WordEmbeddings() *tensor.Dense {
+	if m.nn.e_w == nil {
+		return nil
+	}
 	val := m.nn.e_w.Value().(*tensor.Dense)
 	emb := val.Clone().(*tensor.Dense)
 	return emb
 }
 
+// POSTagEmbeddings returns a copy of the POSTag embeddings. It returns nil if no feature uses POSTags.
 func (m *Model) POSTagEmbeddings() *tensor.Dense {
+	if m.nn.e_t == nil {
+		return nil
+	}
 	val := m.nn.e_t.Value().(*tensor.Dense)
 	emb := val.Clone().(*tensor.Dense)
 	return emb
 }
 
+// FeatureTemplates returns the feature templates that the model extracts its features with.
+func (m *Model) FeatureTemplates() []FeatureTemplate { return m.nn.featureTemplates() }
+
 func (m *Model) String() string {
 	var buf bytes.Buffer
 	buf.WriteString(m.nn.String())
@@ -103,10 +114,8 @@ func LoadReader(rd io.ReadCloser) (*Model, error) {
 		return nil, err
 	}
 
-	if err := decoder.Decode(&m.ts); err != nil {
-		m.ts = transitions
-	}
-	m.nn.transitions = m.ts
+	// the transitions are defined by the transition system of the neural network
+	m.ts = m.nn.transitions
 
 	return m, nil
 
@@ -1,6 +1,6 @@
 package dep
 
-// Move is an action that the dependency parser can take - whether to Shift, Attach-Left, or AttachRight
+// Move is an action that the dependency parser can take - whether to Shift, Attach-Left, AttachRight, Reduce or Swap
 type Move byte
 
 //go:generate stringer -type=Move
@@ -9,9 +9,11 @@ const (
 	Shift Move = iota
 	Left
 	Right
+	Reduce // only used by the arc-eager transition system
+	Swap   // only used by the arc-standard transition system with swaps
 
 	MAXMOVE
 )
 
-// ALLMOVES is the set of all possible moves
+// ALLMOVES is the set of all possible moves of the arc-standard transition system
 var ALLMOVES = [...]Move{Left, Right, Shift}
@@ -4,9 +4,9 @@ package dep
 
 import "fmt"
 
-const _Move_name = "ShiftLeftRightMAXMOVE"
+const _Move_name = "ShiftLeftRightReduceSwapMAXMOVE"
 
-var _Move_index = [...]uint8{0, 5, 9, 14, 21}
+var _Move_index = [...]uint8{0, 5, 9, 14, 20, 24, 31}
 
 func (i Move) String() string {
 	if i >= Move(len(_Move_index)-1) {
@@ -1,6 +1,10 @@
 package dep
 
 import (
+	"math"
+	"math/rand"
+	"sync"
+
 	"github.com/chewxy/lingo"
 	"github.com/chewxy/lingo/corpus"
 	"github.com/pkg/errors"
@@ -43,29 +47,37 @@ type neuralnetwork2 struct {
 
 	// model
 
-	// embedding matrices for word, POSTags and labels respectively
-	e_w *G.Node // Shape: (EmbeddingSize, DictSize)
-	e_t *G.Node // Shape: (EmbeddingSize, lingo.MAXTAG)
-	e_l *G.Node // Shape: (EmbeddingSize, lingo.MAXDEP)
+	// embedding matrices for word, POSTags, labels and hashed features respectively
+	e_w *G.Node // Shape: (DictSize, EmbeddingSize)
+	e_t *G.Node // Shape: (lingo.MAXTAG, EmbeddingSize)
+	e_l *G.Node // Shape: (lingo.MAXDEP, EmbeddingSize)
+	e_s *G.Node // Shape: (hashBuckets, EmbeddingSize)
 
 	// w1
-	w1_w *G.Node // Shape: (HiddenSize, DictSize)
-	w1_t *G.Node // Shape: (HiddenSize, lingo.MAXTAG)
-	w1_l *G.Node // Shape: (HiddenSize, lingo.MAXDEP)
+	w1_w *G.Node // Shape: (HiddenSize, EmbeddingSize * word features)
+	w1_t *G.Node // Shape: (HiddenSize, EmbeddingSize * POSTag features)
+	w1_l *G.Node // Shape: (HiddenSize, EmbeddingSize * label features)
+	w1_s *G.Node // Shape: (HiddenSize, EmbeddingSize * hashed features)
 	b    *G.Node // Shape: (HiddenSize)
 
 	// w2
-	w2 *G.Node // Shape: (MAXTRANSITION, HiddenSize)
+	w2 *G.Node // Shape: (len(transitions), HiddenSize)
 
-	// selects
-	x_wSelW G.Nodes // 18 - word features
-	x_tSelT G.Nodes // 18 - POSTag features
-	x_lSelL G.Nodes // 12 - Dependency feature
+	// selects. The number of each is defined by the feature templates (18, 18, 12 and 0 by default).
+	// The nodes of a kind of embedding that no feature uses are nil
+	x_wSelW G.Nodes // word features
+	x_tSelT G.Nodes // POSTag features
+	x_lSelL G.Nodes // Dependency feature
+	x_sSelS G.Nodes // hashed features
 
 	// inputs (feature vectors built up from the selects)
 	x_w *G.Node
 	x_t *G.Node
 	x_l *G.Node
+	x_s *G.Node
+
+	// dropout mask of the hidden layer. It is nil if there's no dropout
+	dropMask *G.Node
 
 	// outputs
 	scores  *G.Node // argmax this to get the greedy decoded transition
@@ -75,13 +87,20 @@ type neuralnetwork2 struct {
 
 	vm     G.VM
 	model  G.Nodes
-	solver G.Solver
+	solver solver
 
 	dict        *corpus.Corpus
 	transitions []transition
+	fs          *featureSet
+	frozen      map[int][]float64 // rows of e_w that are kept as they are while training
+	rng         *rand.Rand        // random numbers for initializing the weights and dropout. If nil, a fixed seed is used
 
 	costChan chan G.Value
 
+	// inference
+	pcLock sync.Mutex
+	pc     *precomputed // built lazily, and invalidated whenever the weights change
+
 	// wordfeats *G.Node
 	// tagfeats  *G.Node
 	// depfeats  *G.Node
@@ -89,14 +108,53 @@ type neuralnetwork2 struct {
 	// act       *G.Node
 }
 
+// embeddingGroup is one kind of embedding in the neural network, along with the nodes that use it
+type embeddingGroup struct {
+	name   string // the suffix of the names of the nodes
+	e, w1  **G.Node
+	x      **G.Node
+	sel    *G.Nodes
+	rows   int // number of embeddings
+	feats  int // number of features
+	offset int // the IDs in the feature vector are offset by this much (see featureSet.extract)
+}
+
+// groups returns the kinds of embeddings in the neural network. The order is the order of the features in the feature vector
+func (nn *neuralnetwork2) groups() [MAXEMBEDDING]embeddingGroup {
+	var words int
+	if nn.dict != nil {
+		words = nn.dict.Size()
+	}
+	// In any case a very very very small dict was passed in
+	// we set the minimum to the number of word features
+	if wordFeats := nn.fs.count(wordEmbedding); words < wordFeats {
+		words = wordFeats
+	}
+
+	return [MAXEMBEDDING]embeddingGroup{
+		{"w", &nn.e_w, &nn.w1_w, &nn.x_w, &nn.x_wSelW, words, nn.fs.count(wordEmbedding), wordFeatsStartAt},
+		{"t", &nn.e_t, &nn.w1_t, &nn.x_t, &nn.x_tSelT, int(lingo.MAXTAG), nn.fs.count(tagEmbedding), posFeatsStartAt},
+		{"l", &nn.e_l, &nn.w1_l, &nn.x_l, &nn.x_lSelL, int(lingo.MAXDEPTYPE), nn.fs.count(labelEmbedding), labelFeatsStartAt},
+		{"s", &nn.e_s, &nn.w1_s, &nn.x_s, &nn.x_sSelS, hashBuckets, nn.fs.count(hashedEmbedding), 0},
+	}
+}
+
 func (nn *neuralnetwork2) initialized() bool {
-	return nn.g != nil && nn.sub != nil &&
-		nn.e_w != nil && nn.e_t != nil && nn.e_l != nil &&
-		nn.w1_w != nil && nn.w1_t != nil && nn.w1_l != nil && nn.b != nil &&
-		nn.w2 != nil && len(nn.x_wSelW) > 0 && len(nn.x_tSelT) > 0 && len(nn.x_lSelL) > 0 &&
-		nn.x_w != nil && nn.x_t != nil && nn.x_l != nil &&
-		nn.scores != nil &&
-		nn.dict != nil && nn.vm != nil && nn.solver != nil
+	if nn.fs == nil || nn.g == nil || nn.sub == nil ||
+		nn.b == nil || nn.w2 == nil || nn.scores == nil ||
+		nn.dict == nil || nn.vm == nil || nn.solver == nil {
+		return false
+	}
+
+	for _, grp := range nn.groups() {
+		if grp.feats == 0 {
+			continue
+		}
+		if *grp.e == nil || *grp.w1 == nil || *grp.x == nil || len(*grp.sel) != grp.feats {
+			return false
+		}
+	}
+	return true
 }
 
 func (nn *neuralnetwork2) init() error {
@@ -104,79 +162,52 @@ func (nn *neuralnetwork2) init() error {
 		return errors.Errorf("No Corpus Provided to the Neural Network. Will be unable to decode")
 	}
 
+	var err error
+	if nn.fs, err = newFeatureSet(nn.featureTemplates()); err != nil {
+		return err
+	}
+
 	g := G.NewGraph()
 	nn.g = g
+	nn.invalidate()
 
-	word := nn.dict.Size()
-	tags := int(lingo.MAXTAG)
-	deps := int(lingo.MAXDEPTYPE)
-	// trns := len(nn.transitions)
-
-	wordFeats := POS_OFFSET - 0
-	tagFeats := DEP_OFFSET - POS_OFFSET
-	depFeats := int(MAXFEATURE) - DEP_OFFSET
-
-	// In any case a very very very small dict was passed in
-	// we set the minimum to wordFeatss
-	if word < wordFeats {
-		word = wordFeats
-	}
+	// the transitions that are scored depend on the transition system
+	nn.transitions = nn.transitionTable()
+	trns := len(nn.transitions)
 
+	groups := nn.groups()
 	logf(`Word: %d
 tags: %d
 deps: %d
 wordFeats: %d
 tagFeats: %d
 depFeats: %d
-`, word, tags, deps, wordFeats, tagFeats, depFeats)
-
-	// define inputs
-	nn.x_w = G.NewVector(g, nn.Dtype, G.WithShape(wordFeats*nn.EmbeddingSize), G.WithName("word input"), G.WithInit(G.Zeroes()))
-	nn.x_t = G.NewVector(g, nn.Dtype, G.WithShape(tagFeats*nn.EmbeddingSize), G.WithName("POSTag input"), G.WithInit(G.Zeroes()))
-	nn.x_l = G.NewVector(g, nn.Dtype, G.WithShape(depFeats*nn.EmbeddingSize), G.WithName("word input"), G.WithInit(G.Zeroes()))
-
-	nn.x_wSelW = make(G.Nodes, wordFeats)
-	nn.x_tSelT = make(G.Nodes, tagFeats)
-	nn.x_lSelL = make(G.Nodes, depFeats)
-
-	// define models
-	nn.e_w = G.NewMatrix(g, nn.Dtype, G.WithShape(word, nn.EmbeddingSize), G.WithName("e_w"), G.WithInit(G.GlorotU(1)))
-	nn.e_t = G.NewMatrix(g, nn.Dtype, G.WithShape(tags, nn.EmbeddingSize), G.WithName("e_t"), G.WithInit(G.GlorotU(1)))
-	nn.e_l = G.NewMatrix(g, nn.Dtype, G.WithShape(deps, nn.EmbeddingSize), G.WithName("e_l"), G.WithInit(G.GlorotU(1)))
-
-	nn.w1_w = G.NewMatrix(g, nn.Dtype, G.WithShape(nn.HiddenSize, nn.EmbeddingSize*wordFeats), G.WithName("w1_w"), G.WithInit(G.GlorotU(1)))
-	nn.w1_t = G.NewMatrix(g, nn.Dtype, G.WithShape(nn.HiddenSize, nn.EmbeddingSize*tagFeats), G.WithName("w1_t"), G.WithInit(G.GlorotU(1)))
-	nn.w1_l = G.NewMatrix(g, nn.Dtype, G.WithShape(nn.HiddenSize, nn.EmbeddingSize*depFeats), G.WithName("w1_l"), G.WithInit(G.GlorotU(1)))
-	nn.b = G.NewVector(g, nn.Dtype, G.WithShape(nn.HiddenSize), G.WithName("b"), G.WithInit(G.Zeroes()))
-
-	nn.w2 = G.NewMatrix(g, nn.Dtype, G.WithShape(MAXTRANSITION, nn.HiddenSize), G.WithName("w2"), G.WithInit(G.GlorotU(1)))
-
-	nn.model = G.Nodes{nn.e_w, nn.e_t, nn.e_l, nn.w1_w, nn.w1_t, nn.w1_l, nn.b, nn.w2}
-
-	// define selects
-	// words first
-	logf("nn.e_w: %+1.1s", nn.e_w.Value())
-	var err error
-	for i := 0; i < wordFeats; i++ {
-		if nn.x_wSelW[i], err = G.Slice(nn.e_w, G.S(i)); err != nil { // dummy slices... they'll be replaced at runtime
-			return err
+hashedFeats: %d
+`, groups[wordEmbedding].rows, lingo.MAXTAG, lingo.MAXDEPTYPE, groups[wordEmbedding].feats, groups[tagEmbedding].feats, groups[labelEmbedding].feats, groups[hashedEmbedding].feats)
+
+	// define models, and the selects of each feature
+	nn.model = nn.model[:0]
+	for _, grp := range groups {
+		*grp.e, *grp.w1, *grp.x, *grp.sel = nil, nil, nil, nil
+		if grp.feats == 0 {
+			continue
 		}
 
-	}
+		*grp.e = G.NewMatrix(g, nn.Dtype, G.WithShape(grp.rows, nn.EmbeddingSize), G.WithName("e_"+grp.name), G.WithInit(glorotU(nn.random(), 1)))
+		*grp.w1 = G.NewMatrix(g, nn.Dtype, G.WithShape(nn.HiddenSize, nn.EmbeddingSize*grp.feats), G.WithName("w1_"+grp.name), G.WithInit(glorotU(nn.random(), 1)))
+		nn.model = append(nn.model, *grp.e, *grp.w1)
 
-	// tag features
-	for i := 0; i < tagFeats; i++ {
-		if nn.x_tSelT[i], err = G.Slice(nn.e_t, G.S(i)); err != nil { // dummy slices... they'll be replaced at runtime
-			return err
-		}
-	}
-
-	// dependency features
-	for i := 0; i < depFeats; i++ {
-		if nn.x_lSelL[i], err = G.Slice(nn.e_l, G.S(i)); err != nil {
-			return err
+		sel := make(G.Nodes, grp.feats)
+		for i := range sel {
+			if sel[i], err = G.Slice(*grp.e, G.S(i)); err != nil { // dummy slices... they'll be replaced at runtime
+				return err
+			}
 		}
+		*grp.sel = sel
 	}
+	nn.b = G.NewVector(g, nn.Dtype, G.WithShape(nn.HiddenSize), G.WithName("b"), G.WithInit(G.Zeroes()))
+	nn.w2 = G.NewMatrix(g, nn.Dtype, G.WithShape(trns, nn.HiddenSize), G.WithName("w2"), G.WithInit(glorotU(nn.random(), 1)))
+	nn.model = append(nn.model, nn.b, nn.w2)
 
 	// forwards
 	if err = nn.fwd(); err != nil {
@@ -203,7 +234,9 @@ depFeats: %d
 	// nn.vm = G.NewTapeMachine(prog, locmap, G.BindDualValues(nn.model...), G.UseCudaFor())
 	nn.vm = G.NewTapeMachine(nn.g, G.BindDualValues(nn.model...), G.UseCudaFor())
 	G.BindDualValues(nn.scores)(nn.vm) // makes sure that scores is a *dualValue
-	nn.solver = G.NewAdaGradSolver(G.WithLearnRate(nn.AdaAlpha), G.WithEps(nn.AdaEps), G.WithL2Reg(nn.Reg), G.WithBatchSize(float64(nn.BatchSize)))
+	if nn.solver, err = newSolver(nn.NNConfig); err != nil {
+		return err
+	}
 	// nn.solver = G.NewVanillaSolver(G.WithLearnRate(nn.AdaAlpha), G.WithL2Reg(nn.Reg))
 	return nil
 }
@@ -211,54 +244,43 @@ depFeats: %d
 func (nn *neuralnetwork2) fwd() error {
 	var err error
 
-	// build up x vectors
-	if nn.x_w, err = G.Concat(0, nn.x_wSelW...); err != nil {
-		return err
-	}
-
-	if nn.x_t, err = G.Concat(0, nn.x_tSelT...); err != nil {
-		return err
-	}
-
-	if nn.x_l, err = G.Concat(0, nn.x_lSelL...); err != nil {
-		return err
-	}
-
-	logf("w1_w %v, x_w %v", nn.w1_w.Shape(), nn.x_w.Shape())
-	m_w := &may{nil, nn.w1_w}
-	m_w.doBinary(G.Mul, nn.x_w)
-	if m_w.error != nil {
-		return m_w.error
-	}
+	// build up x vectors, and multiply them with w1
+	var layer1 G.Nodes
+	for _, grp := range nn.groups() {
+		if grp.feats == 0 {
+			continue
+		}
 
-	logf("w1_t %v, x_t %v", nn.w1_t.Shape(), nn.x_t.Shape())
-	m_t := &may{nil, nn.w1_t}
-	m_t.doBinary(G.Mul, nn.x_t)
-	if m_t.error != nil {
-		return m_t.error
-	}
+		if *grp.x, err = G.Concat(0, *grp.sel...); err != nil {
+			return err
+		}
 
-	logf("w1_l %v, x_l %v", nn.w1_l.Shape(), nn.x_l.Shape())
-	m_l := &may{nil, nn.w1_l}
-	m_l.doBinary(G.Mul, nn.x_l)
-	if m_l.error != nil {
-		return m_l.error
+		logf("w1_%s %v, x_%s %v", grp.name, (*grp.w1).Shape(), grp.name, (*grp.x).Shape())
+		m := &may{nil, *grp.w1}
+		m.doBinary(G.Mul, *grp.x)
+		if m.error != nil {
+			return m.error
+		}
+		layer1 = append(layer1, m.n)
 	}
 
 	// add and activate layer 1
-	logf("w : %v", m_w.n.Shape())
-	m_w1 := &may{nil, m_w.n}
-	m_w1.doBinary(G.Add, m_t.n)
-	m_w1.doBinary(G.Add, m_l.n)
+	m_w1 := &may{nil, layer1[0]}
+	for _, n := range layer1[1:] {
+		m_w1.doBinary(G.Add, n)
+	}
 	m_w1.doBinary(G.Add, nn.b)
 	m_w1.doUnary(G.Cube)
 	if m_w1.error != nil {
 		return m_w1.error
 	}
 
+	// the dropout mask is drawn from nn.rng before each example, instead of using G.Dropout, so training can be repeated exactly
+	nn.dropMask = nil
 	if nn.Dropout > 0 {
 		logf("Doing dropout")
-		m_w1.n, m_w1.error = G.Dropout(m_w1.n, nn.Dropout)
+		nn.dropMask = G.NewVector(nn.g, nn.Dtype, G.WithShape(nn.HiddenSize), G.WithName("dropout"), G.WithInit(G.Ones()))
+		m_w1.doBinary(G.HadamardProd, nn.dropMask)
 		if m_w1.error != nil {
 			return m_w1.error
 		}
@@ -301,7 +323,6 @@ func (nn *neuralnetwork2) train(examples []example) error {
 	if nn.BatchSize > size {
 		batches = 1
 		end = size
-		G.WithBatchSize(float64(size))(nn.solver) // set it such that the solver doesn't get confused
 	} else {
 		end = nn.BatchSize
 	}
@@ -309,6 +330,9 @@ func (nn *neuralnetwork2) train(examples []example) error {
 	for batch := 0; batch < batches; batch++ {
 		for _, ex := range examples[start:end] {
 			nn.feats2vec(ex.features)
+			if err := nn.dropout(); err != nil {
+				return err
+			}
 			tid := lookupTransition(ex.transition, nn.transitions)
 
 			if err := G.UnsafeLet(nn.cost, G.S(tid)); err != nil {
@@ -325,6 +349,12 @@ func (nn *neuralnetwork2) train(examples []example) error {
 			err = errors.Wrapf(err, "Stepping on the model failed %v", batch)
 			return err
 		}
+		if len(nn.frozen) > 0 {
+			if err := setRows(nn.e_w.Value(), nn.frozen); err != nil {
+				return err
+			}
+		}
+		nn.invalidate() // the weights have changed
 
 		if nn.costChan != nil {
 			nn.costChan <- nn.costVal
@@ -343,9 +373,23 @@ func (nn *neuralnetwork2) train(examples []example) error {
 	return nil
 }
 
-// pred predicts the index of the transitions
+// pred predicts the index of the transitions. It is safe for concurrent use.
+// When predicting many transitions, use a scratch from newScratch() instead, which also keeps the scores of each transition.
 func (nn *neuralnetwork2) pred(ind []int) (int, error) {
+	s, err := nn.newScratch()
+	if err != nil {
+		return 0, err
+	}
+	return s.pred(ind), nil
+}
+
+// predGraph returns the scores of the transitions by running the expression graph.
+// It is much slower than pred, and is not safe for concurrent use as it modifies the graph. It's kept as a reference to check pred against.
+func (nn *neuralnetwork2) predGraph(ind []int) ([]float64, error) {
 	nn.feats2vec(ind)
+	if err := nn.keepAll(); err != nil {
+		return nil, err
+	}
 
 	// f, _ := os.OpenFile("LOOOOOG", os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
 	// logger := log.New(f, "", 0)
@@ -354,41 +398,133 @@ func (nn *neuralnetwork2) pred(ind []int) (int, error) {
 	// m := G.NewLispMachine(nn.sub, G.ExecuteFwdOnly(), G.WithLogger(logger), G.WithWatchlist(), G.LogBothDir(), G.WithValueFmt("%+3.3v"))
 	m := G.NewLispMachine(nn.sub, G.ExecuteFwdOnly())
 	if err := m.RunAll(); err != nil {
-		return 0, err
+		return nil, err
 	}
 	// logger.Println("========================\n")
 
-	val := nn.scores.Value().(tensor.Tensor)
-	t, err := tensor.Argmax(val, tensor.AllAxes)
-	if err != nil {
-		return 0, err
+	return float64s(nn.scores.Value())
+}
+
+// getPrecomputed returns the precomputed network, building it if the weights have changed since it was last built. It is safe for concurrent use.
+func (nn *neuralnetwork2) getPrecomputed() (*precomputed, error) {
+	nn.pcLock.Lock()
+	defer nn.pcLock.Unlock()
+	if nn.pc == nil {
+		var err error
+		if nn.pc, err = newPrecomputed(nn); err != nil {
+			return nil, err
+		}
 	}
+	return nn.pc, nil
+}
 
-	return t.ScalarValue().(int), nil
+// invalidate throws away the precomputed network. It has to be called whenever the weights change.
+func (nn *neuralnetwork2) invalidate() {
+	nn.pcLock.Lock()
+	nn.pc = nil
+	nn.pcLock.Unlock()
+}
+
+// newScratch creates the state needed to predict transitions with the current weights.
+func (nn *neuralnetwork2) newScratch() (*scratch, error) {
+	pc, err := nn.getPrecomputed()
+	if err != nil {
+		return nil, err
+	}
+	return &scratch{
+		pc:     pc,
+		hidden: make([]float64, pc.hidden),
+		scores: make([]float64, pc.trns),
+	}, nil
 }
 
 // utility function
 
 func (nn *neuralnetwork2) feats2vec(indicators []int) error {
-	// fix word features
-	for i, ind := range indicators[:POS_OFFSET] {
-		if err := G.UnsafeLet(nn.x_wSelW[i], G.S(ind-wordFeatsStartAt)); err != nil {
-			return err
+	for _, grp := range nn.groups() {
+		for i, ind := range indicators[:grp.feats] {
+			if err := G.UnsafeLet((*grp.sel)[i], G.S(ind-grp.offset)); err != nil {
+				return err
+			}
 		}
+		indicators = indicators[grp.feats:]
 	}
+	return nil
+}
 
-	// fix tag features
-	for i, ind := range indicators[POS_OFFSET:DEP_OFFSET] {
-		if err := G.UnsafeLet(nn.x_tSelT[i], G.S(ind)); err != nil {
-			return err
-		}
+// random returns the random numbers used by the neural network
+func (nn *neuralnetwork2) random() *rand.Rand {
+	if nn.rng == nil {
+		nn.rng = rand.New(rand.NewSource(0))
 	}
+	return nn.rng
+}
 
-	for i, ind := range indicators[DEP_OFFSET:] {
-		if err := G.UnsafeLet(nn.x_lSelL[i], G.S(ind-labelFeatsStartAt)); err != nil {
-			return err
+// dropout draws a new dropout mask. Each unit of the hidden layer is kept with a probability of 1 - Dropout.
+// The units that are kept are scaled by 1/(1 - Dropout), so the expected activation is the same as when parsing, where all the units are kept as they are.
+func (nn *neuralnetwork2) dropout() error {
+	if nn.dropMask == nil {
+		return nil
+	}
+	mask, writeBack, err := float64Data(nn.dropMask.Value().(*tensor.Dense))
+	if err != nil {
+		return err
+	}
+	r := nn.random()
+	for i := range mask {
+		mask[i] = 0
+		if r.Float64() > nn.Dropout {
+			mask[i] = 1 / (1 - nn.Dropout)
 		}
 	}
+	writeBack()
+	return nil
+}
 
+// keepAll sets the dropout mask to keep all the units of the hidden layer as they are, as when parsing
+func (nn *neuralnetwork2) keepAll() error {
+	if nn.dropMask == nil {
+		return nil
+	}
+	mask, writeBack, err := float64Data(nn.dropMask.Value().(*tensor.Dense))
+	if err != nil {
+		return err
+	}
+	for i := range mask {
+		mask[i] = 1
+	}
+	writeBack()
 	return nil
 }
+
+// glorotU is G.GlorotU, but with the random numbers drawn from r
+func glorotU(r *rand.Rand, gain float64) G.InitWFn {
+	f := func(dt tensor.Dtype, s ...int) interface{} {
+		// the shapes of the weights are (rows, cols), or (cols) for vectors
+		n1, n2 := 1, s[0]
+		if len(s) > 1 {
+			n1, n2 = s[0], s[1]
+		}
+		stdev := gain * math.Sqrt(2.0/float64(n1+n2))
+		hi := math.Sqrt(3.0) * stdev
+
+		size := tensor.Shape(s).TotalSize()
+		switch dt {
+		case tensor.Float64:
+			retVal := make([]float64, size)
+			for i := range retVal {
+				retVal[i] = (r.Float64()*2 - 1) * hi
+			}
+			return retVal
+		case tensor.Float32:
+			retVal := make([]float32, size)
+			for i := range retVal {
+				retVal[i] = float32((r.Float64()*2 - 1) * hi)
+			}
+			return retVal
+		default:
+			panic(errors.Errorf("Unsupported Dtype %v for GlorotU", dt))
+		}
+	}
+	return f
+}
@@ -21,21 +21,54 @@ Info
 Embeddings_Word       : %v
 Embeddings_POStag     : %v
 Embeddings_Dependency : %v
+Embeddings_Hashed     : %v
 Selects_Words         : %d
 Selects_POSTag        : %d
 Selects_Dependency    : %d
+Selects_Hashed        : %d
 Weights1_Word         : %v
 Weights1_POSTag       : %v
 Weights1_Dependency   : %v
+Weights1_Hashed       : %v
 Biases                : %v
 Weights2              : %v
 `
 
 	return fmt.Sprintf(s, nn.NNConfig,
-		nn.e_w.Shape(), nn.e_t.Shape(), nn.e_l.Shape(),
-		len(nn.x_wSelW), len(nn.x_tSelT), len(nn.x_lSelL),
-		nn.w1_w.Shape(), nn.w1_t.Shape(), nn.w1_l.Shape(),
-		nn.b.Shape(), nn.w2.Shape())
+		shapeOf(nn.e_w), shapeOf(nn.e_t), shapeOf(nn.e_l), shapeOf(nn.e_s),
+		len(nn.x_wSelW), len(nn.x_tSelT), len(nn.x_lSelL), len(nn.x_sSelS),
+		shapeOf(nn.w1_w), shapeOf(nn.w1_t), shapeOf(nn.w1_l), shapeOf(nn.w1_s),
+		shapeOf(nn.b), shapeOf(nn.w2))
+}
+
+// shapeOf returns the shape of a node, or "-" if the node doesn't exist
+func shapeOf(n *G.Node) interface{} {
+	if n == nil {
+		return "-"
+	}
+	return n.Shape()
+}
+
+// serialized returns the nodes that hold the weights of the model, in the order they're serialized.
+// The hashed embeddings come last, so models trained before they were introduced can still be loaded.
+func (nn *neuralnetwork2) serialized() G.Nodes {
+	groups := nn.groups()
+	var retVal G.Nodes
+	for _, grp := range groups[:hashedEmbedding] {
+		if grp.feats > 0 {
+			retVal = append(retVal, *grp.e)
+		}
+	}
+	for _, grp := range groups[:hashedEmbedding] {
+		if grp.feats > 0 {
+			retVal = append(retVal, *grp.w1)
+		}
+	}
+	retVal = append(retVal, nn.b, nn.w2)
+	if groups[hashedEmbedding].feats > 0 {
+		retVal = append(retVal, nn.e_s, nn.w1_s)
+	}
+	return retVal
 }
 
 func (nn *neuralnetwork2) GobEncode() ([]byte, error) {
@@ -50,36 +83,10 @@ func (nn *neuralnetwork2) GobEncode() ([]byte, error) {
 		return nil, err
 	}
 
-	if err := encoder.Encode(nn.e_w.Value()); err != nil {
-		return nil, err
-	}
-
-	if err := encoder.Encode(nn.e_t.Value()); err != nil {
-		return nil, err
-	}
-
-	if err := encoder.Encode(nn.e_l.Value()); err != nil {
-		return nil, err
-	}
-
-	if err := encoder.Encode(nn.w1_w.Value()); err != nil {
-		return nil, err
-	}
-
-	if err := encoder.Encode(nn.w1_t.Value()); err != nil {
-		return nil, err
-	}
-
-	if err := encoder.Encode(nn.w1_l.Value()); err != nil {
-		return nil, err
-	}
-
-	if err := encoder.Encode(nn.b.Value()); err != nil {
-		return nil, err
-	}
-
-	if err := encoder.Encode(nn.w2.Value()); err != nil {
-		return nil, err
+	for _, n := range nn.serialized() {
+		if err := encoder.Encode(n.Value()); err != nil {
+			return nil, errors.Wrapf(err, "Unable to encode %v", n.Name())
+		}
 	}
 	return buf.Bytes(), nil
 }
@@ -101,53 +108,14 @@ func (nn *neuralnetwork2) GobDecode(buf []byte) error {
 		return err
 	}
 
-	e_w := T.New(T.Of(nn.Dtype), T.WithShape(nn.e_w.Shape()...))
-	if err := decoder.Decode(e_w); err != nil {
-		return err
-	}
-	G.Let(nn.e_w, e_w)
-
-	e_t := T.New(T.Of(nn.Dtype), T.WithShape(nn.e_t.Shape()...))
-	if err := decoder.Decode(e_t); err != nil {
-		return err
-	}
-	G.Let(nn.e_t, e_t)
-
-	e_l := T.New(T.Of(nn.Dtype), T.WithShape(nn.e_l.Shape()...))
-	if err := decoder.Decode(e_l); err != nil {
-		return err
-	}
-	G.Let(nn.e_l, e_l)
-
-	w1_w := T.New(T.Of(nn.Dtype), T.WithShape(nn.w1_w.Shape()...))
-	if err := decoder.Decode(w1_w); err != nil {
-		return err
-	}
-	G.Let(nn.w1_w, w1_w)
-
-	w1_t := T.New(T.Of(nn.Dtype), T.WithShape(nn.w1_t.Shape()...))
-	if err := decoder.Decode(w1_t); err != nil {
-		return err
-	}
-	G.Let(nn.w1_t, w1_t)
-
-	w1_l := T.New(T.Of(nn.Dtype), T.WithShape(nn.w1_l.Shape()...))
-	if err := decoder.Decode(w1_l); err != nil {
-		return err
-	}
-	G.Let(nn.w1_l, w1_l)
-
-	bias := T.New(T.Of(nn.Dtype), T.WithShape(nn.b.Shape()...))
-	if err := decoder.Decode(bias); err != nil {
-		return err
-	}
-	G.Let(nn.b, bias)
-
-	w2 := T.New(T.Of(nn.Dtype), T.WithShape(nn.w2.Shape()...))
-	if err := decoder.Decode(w2); err != nil {
-		return err
+	for _, n := range nn.serialized() {
+		val := T.New(T.Of(nn.Dtype), T.WithShape(n.Shape()...))
+		if err := decoder.Decode(val); err != nil {
+			return errors.Wrapf(err, "Unable to decode %v", n.Name())
+		}
+		G.Let(n, val)
 	}
-	G.Let(nn.w2, w2)
+	nn.invalidate()
 
 	return nil
 }
@@ -36,18 +36,30 @@ Number Precomputed       : 30000
 Evaluate Per 100 Iterations
 Clear Gradients Per 0 Iterations
 Dtype: float64
+Transition System: ArcStandard
+Joint Tagging: false
+Feature Templates: 48
+Solver: adagrad
+Momentum: 0.900000
+Decay: 0.999000
+Learn Rate Schedule: constant
+Gradient Clipping: 0.000000
+Gradient Norm Clipping: 0.000000
 
 Info
 ------
 Embeddings_Word       : (74, 50)
 Embeddings_POStag     : (%d, 50)
 Embeddings_Dependency : (%d, 50)
+Embeddings_Hashed     : -
 Selects_Words         : 18
 Selects_POSTag        : 18
 Selects_Dependency    : 12
+Selects_Hashed        : 0
 Weights1_Word         : (200, 900)
 Weights1_POSTag       : (200, 900)
 Weights1_Dependency   : (200, 600)
+Weights1_Hashed       : -
 Biases                : (200)
 Weights2              : (%d, 200)
 `
@@ -1,16 +1,18 @@
 package dep
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/chewxy/lingo/corpus"
 	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
 )
 
 func TestNN2(t *testing.T) {
-	rand.Seed(1337)
+	rng := rand.New(rand.NewSource(1337))
 
 	// we test 50 iterations unless the short flag is passed in
 	epochs := 50
@@ -47,14 +49,14 @@ func TestNN2(t *testing.T) {
 		sig <- struct{}{}
 	}(ch, sigChan)
 
-	exs := makeExamples(sts, nn.NNConfig, nn.dict, transitions, dummyFix{})
+	exs := makeExamples(sts, nn.NNConfig, nn.fs, nn.dict, transitions, dummyFix{})
 
 	start := time.Now()
 	for i := 0; i < epochs; i++ {
 		if err := nn.train(exs); err != nil {
 			t.Errorf("%+v", err)
 		}
-		shuffleExamples(exs)
+		shuffleExamples(exs, rng)
 	}
 	// simulate what *DependencyParser would do
 	close(nn.costChan)
@@ -73,7 +75,7 @@ func TestNN2(t *testing.T) {
 	// PREDICTION TIME!
 
 	ss2 := simpleSentence()
-	exs = makeExamples(ss2, nn.NNConfig, nn.dict, transitions, dummyFix{})
+	exs = makeExamples(ss2, nn.NNConfig, nn.fs, nn.dict, transitions, dummyFix{})
 	start = time.Now()
 	for i, ex := range exs {
 		ind, err := nn.pred(ex.features)
@@ -86,3 +88,38 @@ func TestNN2(t *testing.T) {
 	}
 	t.Logf("Pred Time Taken: %v", time.Since(start))
 }
+
+func TestNN2_dropout(t *testing.T) {
+	nn := new(neuralnetwork2)
+	nn.Dropout = 0.3
+	nn.rng = rand.New(rand.NewSource(1337))
+	nn.dropMask = gorgonia.NewVector(gorgonia.NewGraph(), gorgonia.Float32, gorgonia.WithShape(20), gorgonia.WithInit(gorgonia.Ones()))
+
+	activation := make([]float64, 20)
+	for i := range activation {
+		activation[i] = nn.rng.NormFloat64()
+	}
+
+	// on average, the hidden layer is activated as much when training as when parsing
+	const draws = 20000
+	trained := make([]float64, len(activation))
+	for d := 0; d < draws; d++ {
+		if err := nn.dropout(); err != nil {
+			t.Fatal(err)
+		}
+		mask, _, _ := float64Data(nn.dropMask.Value().(*tensor.Dense))
+		for i, m := range mask {
+			trained[i] += activation[i] * m / draws
+		}
+	}
+
+	if err := nn.keepAll(); err != nil {
+		t.Fatal(err)
+	}
+	mask, _, _ := float64Data(nn.dropMask.Value().(*tensor.Dense))
+	for i, m := range mask {
+		if parsed := activation[i] * m; math.Abs(trained[i]-parsed) > 0.05*math.Max(1, math.Abs(parsed)) {
+			t.Errorf("Unit %d: expected the activation when training %v to be the activation when parsing %v", i, trained[i], parsed)
+		}
+	}
+}
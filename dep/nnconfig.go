@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"io"
 
 	"github.com/pkg/errors"
 	"gorgonia.org/tensor"
@@ -23,6 +24,19 @@ type NNConfig struct {
 	ClearGradientsPerIteration int     // 0
 
 	Dtype tensor.Dtype
+
+	TransitionSystem TransitionSystem // ArcStandard
+	JointTagging     bool             // false. If true, the parser also POS tags the words, with the POSTag carried by Shift
+
+	Features []FeatureTemplate // nil, which uses DefaultFeatureTemplates()
+
+	// AdaAlpha is the learn rate, and AdaEps the smoothing factor, of every solver
+	Solver   SolverType // AdaGrad
+	Momentum float64    // 0.9. Used by SGD, and as the decay rate of the running average of the gradients of Adam
+	Decay    float64    // 0.999. The decay rate of the running average of the squared gradients of RMSProp and Adam
+	Schedule Schedule   // constant learn rate
+	Clip     float64    // 0. If > 0, each gradient is clipped to [-Clip, Clip]
+	ClipNorm float64    // 0. If > 0, the gradients are scaled down so their L2 norm is at most ClipNorm
 }
 
 func (c NNConfig) String() string {
@@ -38,8 +52,34 @@ Number Precomputed       : %d
 Evaluate Per %d Iterations
 Clear Gradients Per %d Iterations
 Dtype: %v
+Transition System: %v
+Joint Tagging: %t
+Feature Templates: %d
+Solver: %v
+Momentum: %f
+Decay: %f
+Learn Rate Schedule: %v
+Gradient Clipping: %f
+Gradient Norm Clipping: %f
 `
-	return fmt.Sprintf(s, c.BatchSize, c.Dropout, c.AdaEps, c.AdaAlpha, c.Reg, c.HiddenSize, c.EmbeddingSize, c.NumPrecomputed, c.EvalPerIteration, c.ClearGradientsPerIteration, c.Dtype)
+	return fmt.Sprintf(s, c.BatchSize, c.Dropout, c.AdaEps, c.AdaAlpha, c.Reg, c.HiddenSize, c.EmbeddingSize, c.NumPrecomputed, c.EvalPerIteration, c.ClearGradientsPerIteration, c.Dtype, c.TransitionSystem, c.JointTagging, len(c.featureTemplates()),
+		c.Solver, c.Momentum, c.Decay, c.Schedule, c.Clip, c.ClipNorm)
+}
+
+// featureTemplates returns the feature templates of the neural network
+func (c NNConfig) featureTemplates() []FeatureTemplate {
+	if c.Features == nil {
+		return DefaultFeatureTemplates()
+	}
+	return c.Features
+}
+
+// transitionTable returns the transitions that the neural network scores
+func (c NNConfig) transitionTable() []transition {
+	if c.JointTagging {
+		return jointTransitions(c.TransitionSystem.transitions())
+	}
+	return c.TransitionSystem.transitions()
 }
 
 // DefaultNNConfig is the default config that is passed in, for initialization purposses.
@@ -67,6 +107,14 @@ func (c NNConfig) GobEncode() ([]byte, error) {
 	default:
 		return nil, errors.Errorf("Unsupported Dtype to be GobEncoded")
 	}
+
+	// fields added after the Dtype are optional when decoding, so older models can still be loaded
+	optional := []interface{}{c.TransitionSystem, c.JointTagging, formatFeatureTemplates(c.Features), c.Solver, c.Momentum, c.Decay, c.Schedule, c.Clip, c.ClipNorm}
+	for _, v := range optional {
+		if err := encoder.Encode(v); err != nil {
+			return nil, errors.Wrapf(err, "Unable to GobEncode %T", v)
+		}
+	}
 	return buf.Bytes(), nil
 }
 
@@ -95,6 +143,41 @@ func (c *NNConfig) GobDecode(p []byte) error {
 	default:
 		return errors.Errorf("Unsupported Dtype to be GobDecoded: %v", bite)
 	}
+
+	// models saved before transition systems were introduced are arc-standard, and
+	// models saved before the solver could be configured were trained with AdaGrad, with a constant learn rate
+	c.TransitionSystem = ArcStandard
+	c.JointTagging = false
+	c.Features = nil
+	c.Solver, c.Momentum, c.Decay = AdaGrad, DefaultNNConfig.Momentum, DefaultNNConfig.Decay
+	c.Schedule = Schedule{}
+	c.Clip, c.ClipNorm = 0, 0
+
+	// older models end before the fields that were added later, which keep their defaults
+	var features string
+	optional := []interface{}{&c.TransitionSystem, &c.JointTagging, &features, &c.Solver, &c.Momentum, &c.Decay, &c.Schedule, &c.Clip, &c.ClipNorm}
+	for _, v := range optional {
+		err := decoder.Decode(v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Unable to GobDecode %T", v)
+		}
+	}
+
+	if c.TransitionSystem >= MAXTRANSITIONSYSTEM {
+		return errors.Errorf("Unsupported TransitionSystem to be GobDecoded: %v", c.TransitionSystem)
+	}
+	if c.Solver >= MAXSOLVERTYPE {
+		return errors.Errorf("Unsupported Solver to be GobDecoded: %v", c.Solver)
+	}
+	if features != "" {
+		var err error
+		if c.Features, err = ParseFeatureTemplates(features); err != nil {
+			return errors.Wrap(err, "Unable to GobDecode the feature templates")
+		}
+	}
 	return nil
 }
 
@@ -117,5 +200,11 @@ func init() {
 
 		Dtype: tensor.Float64,
 		// Dtype: gorgonia.Float32,
+
+		TransitionSystem: ArcStandard,
+
+		Solver:   AdaGrad,
+		Momentum: 0.9,
+		Decay:    0.999,
 	}
 }
@@ -0,0 +1,219 @@
+package dep
+
+import (
+	"sort"
+
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// precomputed is a copy of the neural network that is used for inference, without building a machine for every prediction.
+//
+// It uses the precomputation trick described by Chen and Manning in "A Fast and Accurate Dependency Parser using Neural Networks" (2014):
+// the hidden layer is a sum of the products of w1 and each embedding, and the embedding for a feature is always multiplied with
+// the same columns of w1. So the products can be computed ahead of time for the most frequent words (NumPrecomputed word-position pairs),
+// and for all POSTags and labels. Products for the rest of the words, and for the hashed features, are computed on the fly.
+//
+// The precomputed values are only valid for the weights they were computed from. They have to be rebuilt when the weights change.
+type precomputed struct {
+	hidden, emb int
+	trns        int
+
+	wordFeats, tagFeats, depFeats, hashFeats int // number of features of each kind
+
+	e_w, e_t, e_l, e_s     []float64     // Shape: (rows, emb). nil if no feature uses the embedding
+	w1_w, w1_t, w1_l, w1_s []float64     // Shape: (hidden, emb*feats)
+	b                      []float64     // Shape: (hidden)
+	w2                     []float64     // Shape: (trns, hidden)
+	w2T                    *tensor.Dense // Shape: (hidden, trns). Used for batched inference
+
+	words      map[int]int // word ID → row in wordCache
+	wordCache  []float64   // Shape: (len(words), wordFeats, hidden)
+	tagCache   []float64   // Shape: (MAXTAG, tagFeats, hidden)
+	labelCache []float64   // Shape: (MAXDEPTYPE, depFeats, hidden)
+}
+
+func newPrecomputed(nn *neuralnetwork2) (*precomputed, error) {
+	pc := &precomputed{
+		hidden: nn.HiddenSize,
+		emb:    nn.EmbeddingSize,
+		trns:   nn.w2.Shape()[0],
+
+		wordFeats: len(nn.x_wSelW),
+		tagFeats:  len(nn.x_tSelT),
+		depFeats:  len(nn.x_lSelL),
+		hashFeats: len(nn.x_sSelS),
+	}
+
+	var err error
+	for _, p := range []struct {
+		dst *[]float64
+		n   *G.Node
+	}{
+		{&pc.e_w, nn.e_w}, {&pc.e_t, nn.e_t}, {&pc.e_l, nn.e_l}, {&pc.e_s, nn.e_s},
+		{&pc.w1_w, nn.w1_w}, {&pc.w1_t, nn.w1_t}, {&pc.w1_l, nn.w1_l}, {&pc.w1_s, nn.w1_s},
+		{&pc.b, nn.b}, {&pc.w2, nn.w2},
+	} {
+		if p.n == nil {
+			continue
+		}
+		if *p.dst, err = float64s(p.n.Value()); err != nil {
+			return nil, errors.Wrapf(err, "Unable to precompute %v", p.n.Name())
+		}
+	}
+
+	w2T := make([]float64, len(pc.w2))
+	for t := 0; t < pc.trns; t++ {
+		for h := 0; h < pc.hidden; h++ {
+			w2T[h*pc.trns+t] = pc.w2[t*pc.hidden+h]
+		}
+	}
+	pc.w2T = tensor.New(tensor.WithShape(pc.hidden, pc.trns), tensor.WithBacking(w2T))
+
+	// most frequent words first
+	var rows int
+	if nn.e_w != nil {
+		rows = nn.e_w.Shape()[0]
+	}
+	ids := make([]int, 0, rows)
+	for id := 0; id < rows && id < nn.dict.Size(); id++ {
+		ids = append(ids, id)
+	}
+	sort.SliceStable(ids, func(i, j int) bool { return nn.dict.IDFreq(ids[i]) > nn.dict.IDFreq(ids[j]) })
+	if pc.wordFeats == 0 {
+		ids = ids[:0]
+	} else if n := nn.NumPrecomputed / pc.wordFeats; len(ids) > n {
+		ids = ids[:n]
+	}
+
+	pc.words = make(map[int]int, len(ids))
+	pc.wordCache = make([]float64, len(ids)*pc.wordFeats*pc.hidden)
+	for row, id := range ids {
+		pc.words[id] = row
+		for pos := 0; pos < pc.wordFeats; pos++ {
+			pc.product(pc.wordCache[(row*pc.wordFeats+pos)*pc.hidden:], pc.w1_w, pc.wordFeats, pos, pc.embedding(pc.e_w, id))
+		}
+	}
+
+	pc.tagCache = pc.precompute(pc.e_t, pc.w1_t, int(lingo.MAXTAG), pc.tagFeats)
+	pc.labelCache = pc.precompute(pc.e_l, pc.w1_l, int(lingo.MAXDEPTYPE), pc.depFeats)
+	logf("Precomputed %d words, %d POSTags and %d labels", len(ids), lingo.MAXTAG, lingo.MAXDEPTYPE)
+	return pc, nil
+}
+
+// precompute computes the products of w1 and all the embeddings at every position
+func (pc *precomputed) precompute(e, w1 []float64, rows, feats int) []float64 {
+	retVal := make([]float64, rows*feats*pc.hidden)
+	for id := 0; id < rows; id++ {
+		for pos := 0; pos < feats; pos++ {
+			pc.product(retVal[(id*feats+pos)*pc.hidden:], w1, feats, pos, pc.embedding(e, id))
+		}
+	}
+	return retVal
+}
+
+func (pc *precomputed) embedding(e []float64, id int) []float64 {
+	return e[id*pc.emb : (id+1)*pc.emb]
+}
+
+// product adds the product of the columns of w1 for the feature position and the embedding to dst
+func (pc *precomputed) product(dst, w1 []float64, feats, pos int, emb []float64) {
+	cols := feats * pc.emb
+	for h := 0; h < pc.hidden; h++ {
+		row := w1[h*cols+pos*pc.emb : h*cols+(pos+1)*pc.emb]
+		var sum float64
+		for k, v := range emb {
+			sum += row[k] * v
+		}
+		dst[h] += sum
+	}
+}
+
+// add adds the cached hidden layer contribution to dst
+func add(dst, cache []float64, offset int) {
+	src := cache[offset : offset+len(dst)]
+	for i, v := range src {
+		dst[i] += v
+	}
+}
+
+// hiddenLayer computes the activated hidden layer given the features. hidden must have length pc.hidden.
+func (pc *precomputed) hiddenLayer(features []int, hidden []float64) {
+	copy(hidden, pc.b)
+	for pos, ind := range features[:pc.wordFeats] {
+		id := ind - wordFeatsStartAt
+		if row, ok := pc.words[id]; ok {
+			add(hidden, pc.wordCache, (row*pc.wordFeats+pos)*pc.hidden)
+			continue
+		}
+		pc.product(hidden, pc.w1_w, pc.wordFeats, pos, pc.embedding(pc.e_w, id))
+	}
+	for pos, ind := range features[pc.wordFeats : pc.wordFeats+pc.tagFeats] {
+		add(hidden, pc.tagCache, (ind*pc.tagFeats+pos)*pc.hidden)
+	}
+	for pos, ind := range features[pc.wordFeats+pc.tagFeats : pc.wordFeats+pc.tagFeats+pc.depFeats] {
+		add(hidden, pc.labelCache, ((ind-labelFeatsStartAt)*pc.depFeats+pos)*pc.hidden)
+	}
+	for pos, ind := range features[pc.wordFeats+pc.tagFeats+pc.depFeats:] {
+		pc.product(hidden, pc.w1_s, pc.hashFeats, pos, pc.embedding(pc.e_s, ind))
+	}
+
+	// cube activation
+	for i, v := range hidden {
+		hidden[i] = v * v * v
+	}
+}
+
+// scores computes the scores of each transition given the features. hidden is used as scratch space, and must have length pc.hidden.
+// The scores are written to and returned in scores, which is allocated if it's too short.
+func (pc *precomputed) scores(features []int, hidden, scores []float64) []float64 {
+	pc.hiddenLayer(features, hidden)
+
+	if cap(scores) < pc.trns {
+		scores = make([]float64, pc.trns)
+	}
+	scores = scores[:pc.trns]
+	for t := range scores {
+		row := pc.w2[t*pc.hidden : (t+1)*pc.hidden]
+		var sum float64
+		for i, v := range hidden {
+			sum += row[i] * v
+		}
+		scores[t] = sum
+	}
+	return scores
+}
+
+// float64s copies the data of a value into a []float64
+func float64s(v G.Value) ([]float64, error) {
+	switch data := v.Data().(type) {
+	case []float32:
+		retVal := make([]float64, len(data))
+		for i, v := range data {
+			retVal[i] = float64(v)
+		}
+		return retVal, nil
+	case []float64:
+		retVal := make([]float64, len(data))
+		copy(retVal, data)
+		return retVal, nil
+	default:
+		return nil, errors.Errorf("Unhandled value type %T", data)
+	}
+}
+
+// scratch is the per-call state used to predict transitions. The precomputed network is shared and read-only,
+// so any number of scratches can be used concurrently, but each scratch must only be used by one goroutine.
+type scratch struct {
+	pc     *precomputed
+	hidden []float64
+	scores []float64 // the scores of each transition from the last prediction
+}
+
+// pred predicts the index of the best transition. The scores of all the transitions are kept in s.scores until the next prediction.
+func (s *scratch) pred(features []int) int {
+	s.scores = s.pc.scores(features, s.hidden, s.scores)
+	return argmax(s.scores)
+}
@@ -0,0 +1,157 @@
+package dep
+
+import (
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"gorgonia.org/tensor"
+)
+
+func precomputeTestTrainer(tb testing.TB, conf NNConfig) *Trainer {
+	sts := allSentences()
+	conf.Dropout = 0 // the expression graph applies dropout when predicting
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		tb.Fatalf("%+v", err)
+	}
+	return trainer
+}
+
+func precomputeTestFeatures(trainer *Trainer) [][]int {
+	var retVal [][]int
+	for _, st := range allSentences() {
+		s := st.AnnotatedSentence(dummyFix{})
+		c := newConfiguration(s, true)
+		for !c.isTerminal() {
+			retVal = append(retVal, trainer.nn.fs.extract(c, trainer.nn.dict))
+			c.apply(c.oracle(s.Dependency()))
+		}
+	}
+	return retVal
+}
+
+func TestPrecomputed(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		dt             tensor.Dtype
+		numPrecomputed int
+	}{
+		{"Float64", tensor.Float64, DefaultNNConfig.NumPrecomputed},
+		{"Float32", tensor.Float32, DefaultNNConfig.NumPrecomputed},
+		{"No words precomputed", tensor.Float64, 0},
+	} {
+		conf := DefaultNNConfig
+		conf.Dtype = tc.dt
+		conf.NumPrecomputed = tc.numPrecomputed
+		trainer := precomputeTestTrainer(t, conf)
+		nn := trainer.nn
+
+		tol := 1e-8
+		if tc.dt == tensor.Float32 {
+			tol = 1e-3
+		}
+
+		for _, features := range precomputeTestFeatures(trainer) {
+			wantScores, err := nn.predGraph(features)
+			if err != nil {
+				t.Fatalf("%v: %+v", tc.name, err)
+			}
+			want := argmax(wantScores)
+
+			sc, err := nn.newScratch()
+			if err != nil {
+				t.Fatalf("%v: %+v", tc.name, err)
+			}
+			got := sc.pred(features)
+			gotScores := sc.scores
+
+			for i := range wantScores {
+				if diff := wantScores[i] - gotScores[i]; diff > tol || diff < -tol {
+					t.Fatalf("%v: score %d differs. Want %v. Got %v", tc.name, i, wantScores[i], gotScores[i])
+				}
+			}
+			if want != got {
+				t.Errorf("%v: Expected transition %v. Got %v", tc.name, want, got)
+			}
+		}
+	}
+}
+
+func TestPrecomputed_invalidation(t *testing.T) {
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	trainer := precomputeTestTrainer(t, conf)
+	features := precomputeTestFeatures(trainer)[0]
+
+	if _, err := trainer.nn.pred(features); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if trainer.nn.pc == nil {
+		t.Fatal("Expected precomputed values to be built")
+	}
+
+	if err := trainer.Train(1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if trainer.nn.pc != nil {
+		t.Error("Expected precomputed values to be invalidated by training")
+	}
+
+	want, err := trainer.nn.predGraph(features)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	sc, err := trainer.nn.newScratch()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	sc.pred(features)
+	got := sc.scores
+	for i := range want {
+		if diff := want[i] - got[i]; diff > 1e-8 || diff < -1e-8 {
+			t.Fatalf("Score %d differs after training. Want %v. Got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func BenchmarkPred(b *testing.B) {
+	trainer := precomputeTestTrainer(b, DefaultNNConfig)
+	features := precomputeTestFeatures(trainer)
+	sc, err := trainer.nn.newScratch()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sc.pred(features[i%len(features)])
+	}
+}
+
+func BenchmarkPredGraph(b *testing.B) {
+	trainer := precomputeTestTrainer(b, DefaultNNConfig)
+	features := precomputeTestFeatures(trainer)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := trainer.nn.predGraph(features[i%len(features)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	trainer := precomputeTestTrainer(b, DefaultNNConfig)
+	p := New(trainer.Model)
+	var sentences []lingo.AnnotatedSentence
+	for _, st := range allSentences() {
+		sentences = append(sentences, st.AnnotatedSentence(dummyFix{}))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Parse(sentences[i%len(sentences)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package dep
+
+import (
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+)
+
+// Rule is a post-processing rule that repairs the trees the parser produces. The rules of a Parser are applied in order after every parse.
+type Rule interface {
+	// Name is the name of the rule, which is used to enable and disable it
+	Name() string
+
+	// Apply repairs the tree in place
+	Apply(d *lingo.Dependency)
+}
+
+type ruleFunc struct {
+	name string
+	fn   func(d *lingo.Dependency)
+}
+
+func (r ruleFunc) Name() string              { return r.name }
+func (r ruleFunc) Apply(d *lingo.Dependency) { r.fn(d) }
+
+// RuleFunc creates a Rule from a function
+func RuleFunc(name string, fn func(d *lingo.Dependency)) Rule {
+	return ruleFunc{name, fn}
+}
+
+// NNPCompounds attaches the words of a run of proper nouns to the last of them as compounds
+var NNPCompounds = RuleFunc("nnp-compound", fixProperNounCompounds)
+
+// DefaultRules returns the rules that a Parser is created with
+func DefaultRules() []Rule {
+	return []Rule{NNPCompounds}
+}
+
+// Change is a change to an arc made by a rule. Heads are -1 when the word has no head.
+type Change struct {
+	Rule      string
+	Dependent int
+
+	OldHead  int
+	NewHead  int
+	OldLabel lingo.DependencyType
+	NewLabel lingo.DependencyType
+}
+
+// ruleState is a rule of a Parser, and whether it is enabled
+type ruleState struct {
+	Rule
+	disabled bool
+}
+
+func defaultRuleStates() []ruleState {
+	rules := DefaultRules()
+	retVal := make([]ruleState, len(rules))
+	for i, r := range rules {
+		retVal[i] = ruleState{Rule: r}
+	}
+	return retVal
+}
+
+// Rules returns the names of the rules of the Parser, in the order they are applied, including the disabled rules.
+func (d *Parser) Rules() []string {
+	d.initRules()
+	retVal := make([]string, len(d.rules))
+	for i, r := range d.rules {
+		retVal[i] = r.Name()
+	}
+	return retVal
+}
+
+// AddRule adds a rule, which is applied after the existing rules. A rule with the same name as an existing rule replaces it.
+//
+// The rules should be set up before the Parser is used concurrently.
+func (d *Parser) AddRule(r Rule) {
+	d.initRules()
+	for i := range d.rules {
+		if d.rules[i].Name() == r.Name() {
+			d.rules[i] = ruleState{Rule: r}
+			return
+		}
+	}
+	d.rules = append(d.rules, ruleState{Rule: r})
+}
+
+// EnableRule enables the rule with the given name.
+func (d *Parser) EnableRule(name string) error { return d.setRule(name, false) }
+
+// DisableRule disables the rule with the given name. Disabled rules are not applied.
+func (d *Parser) DisableRule(name string) error { return d.setRule(name, true) }
+
+func (d *Parser) setRule(name string, disabled bool) error {
+	d.initRules()
+	for i := range d.rules {
+		if d.rules[i].Name() == name {
+			d.rules[i].disabled = disabled
+			return nil
+		}
+	}
+	return errors.Errorf("Unknown rule %q", name)
+}
+
+// initRules sets up the default rules of a Parser that wasn't created by New
+func (d *Parser) initRules() {
+	if d.rules == nil {
+		d.rules = defaultRuleStates()
+	}
+}
+
+// postprocess applies the enabled rules to the tree, and returns the repaired tree and the changes the rules made.
+// The rules repair the tree in place, unless there are constraints. Then each rule is applied to a copy of the tree,
+// and the changes of a rule are only kept if the tree still satisfies the constraints.
+func (d *Parser) postprocess(dep *lingo.Dependency, cons *constraints) (*lingo.Dependency, []Change) {
+	rules := d.rules
+	if rules == nil {
+		rules = defaultRuleStates()
+	}
+
+	var changes []Change
+	heads := make([]int, dep.WordCount())
+	labels := make([]lingo.DependencyType, dep.WordCount())
+	for _, r := range rules {
+		if r.disabled {
+			continue
+		}
+		for i := range heads {
+			heads[i] = dep.Head(i)
+			labels[i] = dep.Label(i)
+		}
+
+		fixed := dep
+		if cons != nil {
+			fixed = cloneDependency(dep)
+		}
+		r.Apply(fixed)
+		if cons != nil {
+			if reason := cons.check(fixed); reason != "" {
+				logf("Rule %q not applied: %v", r.Name(), reason)
+				continue
+			}
+		}
+		dep = fixed
+
+		for i := 1; i < dep.WordCount(); i++ {
+			if dep.Head(i) != heads[i] || dep.Label(i) != labels[i] {
+				changes = append(changes, Change{
+					Rule:      r.Name(),
+					Dependent: i,
+					OldHead:   heads[i],
+					NewHead:   dep.Head(i),
+					OldLabel:  labels[i],
+					NewLabel:  dep.Label(i),
+				})
+			}
+		}
+	}
+	return dep, changes
+}
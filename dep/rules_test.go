@@ -0,0 +1,59 @@
+package dep
+
+import (
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_Rules(t *testing.T) {
+	assert := assert.New(t)
+
+	// President Bush on Tuesday nominated two individuals ...
+	broken := func() *lingo.Dependency {
+		d := mediumSentence()[0].Dependency(dummyFix{})
+		d.Annotation(1).SetHead(d.Annotation(5))
+		d.Annotation(1).DependencyType = lingo.Dep
+		return d
+	}
+
+	p := New(nil)
+	assert.Equal([]string{"nnp-compound"}, p.Rules())
+
+	d := broken()
+	_, changes := p.postprocess(d, nil)
+	assert.Equal([]Change{{Rule: "nnp-compound", Dependent: 1, OldHead: 5, NewHead: 2, OldLabel: lingo.Dep, NewLabel: lingo.Compound}}, changes)
+	assert.Equal(2, d.Head(1))
+
+	// a Parser that wasn't created with New has the default rules
+	_, defaults := new(Parser).postprocess(broken(), nil)
+	assert.Equal(changes, defaults)
+
+	if err := p.DisableRule("nnp-compound"); err != nil {
+		t.Fatal(err)
+	}
+	d = broken()
+	_, changes = p.postprocess(d, nil)
+	assert.Empty(changes)
+	assert.Equal(5, d.Head(1))
+	if err := p.EnableRule("nnp-compound"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.DisableRule("nothing"); err == nil {
+		t.Error("Expected an error disabling a rule that doesn't exist")
+	}
+
+	// user defined rules are applied after the built in rules
+	p.AddRule(RuleFunc("relabel", func(d *lingo.Dependency) {
+		for _, a := range d.AnnotatedSentence {
+			if a.DependencyType == lingo.Compound {
+				a.DependencyType = lingo.Dep
+			}
+		}
+	}))
+	assert.Equal([]string{"nnp-compound", "relabel"}, p.Rules())
+	_, changes = p.postprocess(broken(), nil)
+	assert.Equal("nnp-compound", changes[0].Rule)
+	assert.Equal(Change{Rule: "relabel", Dependent: 1, OldHead: 2, NewHead: 2, OldLabel: lingo.Compound, NewLabel: lingo.Dep}, changes[1])
+}
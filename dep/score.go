@@ -0,0 +1,130 @@
+package dep
+
+import (
+	"math"
+
+	"github.com/chewxy/lingo"
+)
+
+// ScoredParse is a parse of a sentence, along with how confident the parser is in each of its arcs.
+type ScoredParse struct {
+	*lingo.Dependency
+
+	// Confidence is the probability of the transition that attached each word to its head, indexed by the ID of the word.
+	// Words that weren't attached by a transition (the root, and words that were reattached by a Rule) have a confidence of 0.
+	Confidence []float64
+
+	// Margin is the difference between the probability of the transition that attached each word and the probability of
+	// the best other transition that could have been applied instead. A negative margin means that the beam search preferred
+	// a less probable transition, because it led to a more probable parse.
+	Margin []float64
+
+	// Score is the sum of the log-probabilities of the transitions that built the parse
+	Score float64
+
+	// Changes are the changes the rules of the Parser made to the parse
+	Changes []Change
+}
+
+// Arc is an arc of a parse, along with the confidence of the parser in it. See ScoredParse.
+type Arc struct {
+	Head      int
+	Dependent int
+	Label     lingo.DependencyType
+
+	Confidence float64
+	Margin     float64
+}
+
+// Arcs returns the arcs of the parse with a confidence of at least min. Arcs(0) returns all the arcs.
+func (p *ScoredParse) Arcs(min float64) []Arc {
+	var retVal []Arc
+	for i := 1; i < p.WordCount(); i++ {
+		h := p.Head(i)
+		if h < 0 || p.Confidence[i] < min {
+			continue
+		}
+		retVal = append(retVal, Arc{
+			Head:       h,
+			Dependent:  i,
+			Label:      p.Label(i),
+			Confidence: p.Confidence[i],
+			Margin:     p.Margin[i],
+		})
+	}
+	return retVal
+}
+
+// arcScores are the confidences and margins of the arcs of a configuration, as it is being parsed
+type arcScores struct {
+	confidence  []float64
+	margin      []float64
+	score       float64
+	transitions int // the number of transitions that make up the score
+}
+
+func newArcScores(c *configuration) *arcScores {
+	return &arcScores{
+		confidence: make([]float64, c.WordCount()),
+		margin:     make([]float64, c.WordCount()),
+	}
+}
+
+func (s *arcScores) clone() *arcScores {
+	confidence := make([]float64, len(s.confidence))
+	copy(confidence, s.confidence)
+	margin := make([]float64, len(s.margin))
+	copy(margin, s.margin)
+	return &arcScores{confidence: confidence, margin: margin, score: s.score, transitions: s.transitions}
+}
+
+// mean returns the mean log-probability of the transitions
+func (s *arcScores) mean() float64 {
+	if s.transitions == 0 {
+		return 0
+	}
+	return s.score / float64(s.transitions)
+}
+
+// apply applies the transition to the configuration, and records the probability and margin of the transition for the word it attaches, if any.
+// logProb and alt are the log-probabilities of the transition and of the best alternative to it.
+func (s *arcScores) apply(c *configuration, t transition, logProb, alt float64) {
+	_, d, _ := c.effect(t)
+	c.apply(t)
+	s.score += logProb
+	s.transitions++
+
+	if d > 0 {
+		s.confidence[d] = math.Exp(logProb)
+		s.margin[d] = math.Exp(logProb) - math.Exp(alt)
+	}
+}
+
+// finish applies the rules of the parser to the parse. The arcs that the rules change weren't attached by the parser, so their scores are cleared.
+func (s *arcScores) finish(c *configuration, d *Parser) *ScoredParse {
+	dep, changes := d.postprocess(c.Dependency, c.cons)
+	for _, ch := range changes {
+		s.confidence[ch.Dependent] = 0
+		s.margin[ch.Dependent] = 0
+	}
+
+	return &ScoredParse{
+		Dependency: dep,
+		Confidence: s.confidence,
+		Margin:     s.margin,
+		Score:      s.score,
+		Changes:    changes,
+	}
+}
+
+// alternative returns the highest log-probability of the transitions other than ts[j] that can be applied to the configuration.
+// If there are none, it returns -Inf.
+func alternative(c *configuration, ts []transition, logProbs []float64, j int) float64 {
+	alt := math.Inf(-1)
+	for i, t := range ts {
+		if i != j && logProbs[i] > alt && c.canApply(t) {
+			alt = logProbs[i]
+		}
+	}
+	return alt
+}
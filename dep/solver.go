@@ -0,0 +1,334 @@
+package dep
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// SolverType is the optimization algorithm used to train the neural network
+type SolverType byte
+
+const (
+	// AdaGrad scales the learn rate of each weight down by the sum of its squared gradients
+	AdaGrad SolverType = iota
+
+	// Adam keeps running averages of the gradients (decaying by Momentum) and of the squared gradients (decaying by Decay)
+	Adam
+
+	// RMSProp scales the learn rate of each weight down by a running average of its squared gradients, decaying by Decay
+	RMSProp
+
+	// SGD is stochastic gradient descent with momentum. A Momentum of 0 is plain stochastic gradient descent.
+	SGD
+
+	MAXSOLVERTYPE
+)
+
+var solverTypeNames = [...]string{"adagrad", "adam", "rmsprop", "sgd"}
+
+func (s SolverType) String() string {
+	if s >= MAXSOLVERTYPE {
+		return fmt.Sprintf("SolverType(%d)", s)
+	}
+	return solverTypeNames[s]
+}
+
+// ParseSolverType returns the SolverType with the given name
+func ParseSolverType(name string) (SolverType, error) {
+	for i, n := range solverTypeNames {
+		if n == name {
+			return SolverType(i), nil
+		}
+	}
+	return MAXSOLVERTYPE, errors.Errorf("Unknown solver %q", name)
+}
+
+// cached returns the number of values the solver keeps for each weight
+func (s SolverType) cached() int {
+	if s == Adam {
+		return 2
+	}
+	return 1
+}
+
+// DecayType is the way the learn rate decays during training
+type DecayType byte
+
+const (
+	// ConstantRate keeps the learn rate constant
+	ConstantRate DecayType = iota
+
+	// StepDecay multiplies the learn rate by Factor every Steps steps
+	StepDecay
+
+	// CosineDecay decays the learn rate over Steps steps along a cosine curve, down to Factor times the learn rate.
+	// After that, the learn rate stays the same.
+	CosineDecay
+
+	MAXDECAYTYPE
+)
+
+var decayTypeNames = [...]string{"constant", "step", "cosine"}
+
+func (d DecayType) String() string {
+	if d >= MAXDECAYTYPE {
+		return fmt.Sprintf("DecayType(%d)", d)
+	}
+	return decayTypeNames[d]
+}
+
+// ParseDecayType returns the DecayType with the given name
+func ParseDecayType(name string) (DecayType, error) {
+	for i, n := range decayTypeNames {
+		if n == name {
+			return DecayType(i), nil
+		}
+	}
+	return MAXDECAYTYPE, errors.Errorf("Unknown learn rate decay %q", name)
+}
+
+// Schedule is a learn rate schedule. The learn rate of each step (one batch) of training is the AdaAlpha of the NNConfig multiplied by the rate of the schedule.
+// The zero value keeps the learn rate constant.
+type Schedule struct {
+	Decay  DecayType
+	Warmup int     // the rate increases linearly from 0 to 1 over this many steps before it starts decaying
+	Steps  int     // see StepDecay and CosineDecay
+	Factor float64 // see StepDecay and CosineDecay
+}
+
+func (s Schedule) String() string {
+	var warmup string
+	if s.Warmup > 0 {
+		warmup = fmt.Sprintf(" after %d warmup steps", s.Warmup)
+	}
+	switch s.Decay {
+	case StepDecay:
+		return fmt.Sprintf("%v decay by %v every %d steps%s", s.Decay, s.Factor, s.Steps, warmup)
+	case CosineDecay:
+		return fmt.Sprintf("%v decay to %v over %d steps%s", s.Decay, s.Factor, s.Steps, warmup)
+	default:
+		return fmt.Sprintf("%v%s", s.Decay, warmup)
+	}
+}
+
+// rate returns the multiplier of the learn rate at the given step. Steps start at 0.
+func (s Schedule) rate(step int) float64 {
+	if step < s.Warmup {
+		return float64(step+1) / float64(s.Warmup)
+	}
+	step -= s.Warmup
+
+	switch s.Decay {
+	case StepDecay:
+		if s.Steps <= 0 {
+			return 1
+		}
+		return math.Pow(s.Factor, float64(step/s.Steps))
+	case CosineDecay:
+		if s.Steps <= 0 || step >= s.Steps {
+			return s.Factor
+		}
+		cos := (1 + math.Cos(math.Pi*float64(step)/float64(s.Steps))) / 2
+		return s.Factor + (1-s.Factor)*cos
+	default:
+		return 1
+	}
+}
+
+// solver is a G.Solver whose state can be saved and restored, so that training can be resumed exactly where it left off.
+type solver interface {
+	G.Solver
+	gob.GobEncoder
+	gob.GobDecoder
+}
+
+// gradSolver implements the solvers of SolverType. Unlike the solvers in Gorgonia, its state can be saved in a checkpoint.
+//
+// The gradients are clipped before they are used: first the gradients of the whole model are scaled down to an L2 norm of at most clipNorm,
+// then each gradient is clipped to [-clip, clip].
+type gradSolver struct {
+	kind     SolverType
+	eta      float64 // learn rate
+	eps      float64 // smoothing factor
+	l2reg    float64 // l2reg param
+	momentum float64
+	decay    float64
+	clip     float64
+	clipNorm float64
+	schedule Schedule
+
+	steps int           // number of steps taken
+	cache [][][]float64 // for each node of the model, the values the solver keeps for each weight
+}
+
+func newSolver(conf NNConfig) (*gradSolver, error) {
+	if conf.Solver >= MAXSOLVERTYPE {
+		return nil, errors.Errorf("Unknown solver %v", conf.Solver)
+	}
+	if conf.Schedule.Decay >= MAXDECAYTYPE {
+		return nil, errors.Errorf("Unknown learn rate decay %v", conf.Schedule.Decay)
+	}
+	return &gradSolver{
+		kind:     conf.Solver,
+		eta:      conf.AdaAlpha,
+		eps:      conf.AdaEps,
+		l2reg:    conf.Reg,
+		momentum: conf.Momentum,
+		decay:    conf.Decay,
+		clip:     conf.Clip,
+		clipNorm: conf.ClipNorm,
+		schedule: conf.Schedule,
+	}, nil
+}
+
+// Step updates the weights of each node of the model with its gradients, and zeroes the gradients.
+func (s *gradSolver) Step(model []G.ValueGrad) error {
+	if s.cache == nil {
+		s.cache = make([][][]float64, len(model))
+	}
+	if len(s.cache) != len(model) {
+		return errors.Errorf("Expected a model of %d nodes. Got %d", len(s.cache), len(model))
+	}
+
+	ws := make([]*tensor.Dense, len(model))
+	gs := make([]*tensor.Dense, len(model))
+	gds := make([][]float64, len(model))
+	for i, n := range model {
+		grad, err := n.Grad()
+		if err != nil {
+			return errors.Wrapf(err, "No Grad found for node %d", i)
+		}
+		var ok bool
+		if ws[i], ok = n.Value().(*tensor.Dense); !ok {
+			return errors.Errorf("Unhandled value type %T", n.Value())
+		}
+		if gs[i], ok = grad.(*tensor.Dense); !ok {
+			return errors.Errorf("Unhandled grad type %T", grad)
+		}
+		if gds[i], err = float64s(grad); err != nil {
+			return err
+		}
+	}
+
+	scale := 1.0
+	if s.clipNorm > 0 {
+		var norm float64
+		for _, gd := range gds {
+			for _, v := range gd {
+				norm += v * v
+			}
+		}
+		if norm = math.Sqrt(norm); norm > s.clipNorm {
+			scale = s.clipNorm / norm
+		}
+	}
+
+	eta := s.eta * s.schedule.rate(s.steps)
+	s.steps++
+	bias1 := 1 - math.Pow(s.momentum, float64(s.steps))
+	bias2 := 1 - math.Pow(s.decay, float64(s.steps))
+
+	for i := range model {
+		w, writeBack, err := float64Data(ws[i])
+		if err != nil {
+			return err
+		}
+		g := gds[i]
+
+		if len(s.cache[i]) == 0 {
+			s.cache[i] = make([][]float64, s.kind.cached())
+			for j := range s.cache[i] {
+				s.cache[i][j] = make([]float64, len(w))
+			}
+		}
+		c := s.cache[i]
+
+		for j := range w {
+			gj := g[j] * scale
+			if s.clip > 0 {
+				gj = math.Max(-s.clip, math.Min(s.clip, gj))
+			}
+
+			var upd float64
+			switch s.kind {
+			case AdaGrad:
+				c[0][j] += gj * gj
+				upd = -eta * gj / math.Sqrt(c[0][j]+s.eps)
+			case Adam:
+				c[0][j] = s.momentum*c[0][j] + (1-s.momentum)*gj
+				c[1][j] = s.decay*c[1][j] + (1-s.decay)*gj*gj
+				upd = -eta * (c[0][j] / bias1) / (math.Sqrt(c[1][j]/bias2) + s.eps)
+			case RMSProp:
+				c[0][j] = s.decay*c[0][j] + (1-s.decay)*gj*gj
+				upd = -eta * gj / math.Sqrt(c[0][j]+s.eps)
+			case SGD:
+				c[0][j] = s.momentum*c[0][j] - eta*gj
+				upd = c[0][j]
+			}
+			upd -= w[j] * s.l2reg
+			w[j] += upd
+		}
+		writeBack()
+		gs[i].Zero()
+	}
+	return nil
+}
+
+// GobEncode encodes the state of the solver. The hyperparameters come from the NNConfig, so they are not encoded.
+func (s *gradSolver) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+	if err := encoder.Encode(s.steps); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(s.cache); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode decodes the state of the solver.
+func (s *gradSolver) GobDecode(buf []byte) error {
+	decoder := gob.NewDecoder(bytes.NewBuffer(buf))
+	if err := decoder.Decode(&s.steps); err != nil {
+		return err
+	}
+	s.cache = nil
+	if err := decoder.Decode(&s.cache); err != nil {
+		return errors.Wrap(err, "Unable to decode the cached values of the solver")
+	}
+	for _, c := range s.cache {
+		if c != nil && len(c) != s.kind.cached() {
+			return errors.Errorf("Expected %d cached values for each weight of %v. Got %d", s.kind.cached(), s.kind, len(c))
+		}
+	}
+	return nil
+}
+
+// float64Data returns the data of the tensor as a []float64, and a function that writes any changes back into the tensor.
+// The data of float64 tensors is returned as it is.
+func float64Data(t *tensor.Dense) ([]float64, func(), error) {
+	switch data := t.Data().(type) {
+	case []float64:
+		return data, func() {}, nil
+	case []float32:
+		retVal := make([]float64, len(data))
+		for i, v := range data {
+			retVal[i] = float64(v)
+		}
+		writeBack := func() {
+			for i, v := range retVal {
+				data[i] = float32(v)
+			}
+		}
+		return retVal, writeBack, nil
+	default:
+		return nil, nil, errors.Errorf("Unhandled value type %T", data)
+	}
+}
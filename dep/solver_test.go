@@ -0,0 +1,197 @@
+package dep
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// weightGrad is a G.ValueGrad without a graph
+type weightGrad struct{ w, g *tensor.Dense }
+
+func (wg weightGrad) Value() G.Value         { return wg.w }
+func (wg weightGrad) Grad() (G.Value, error) { return wg.g, nil }
+
+func TestSolvers(t *testing.T) {
+	// minimize (w - 3)²
+	cases := []struct {
+		solver SolverType
+		eta    float64
+		dt     tensor.Dtype
+	}{
+		{AdaGrad, 0.5, tensor.Float64},
+		{AdaGrad, 0.5, tensor.Float32},
+		{Adam, 0.1, tensor.Float64},
+		{RMSProp, 0.05, tensor.Float64},
+		{SGD, 0.05, tensor.Float64},
+		{SGD, 0.05, tensor.Float32},
+	}
+	for _, c := range cases {
+		conf := DefaultNNConfig
+		conf.Solver = c.solver
+		conf.AdaAlpha = c.eta
+		conf.Reg = 0
+		s, err := newSolver(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg := weightGrad{
+			w: tensor.New(tensor.Of(c.dt), tensor.WithShape(2)),
+			g: tensor.New(tensor.Of(c.dt), tensor.WithShape(2)),
+		}
+		for i := 0; i < 500; i++ {
+			w, _ := float64s(wg.w)
+			g := []float64{2 * (w[0] - 3), 2 * (w[1] - 3)}
+			grad, writeBack, _ := float64Data(wg.g)
+			copy(grad, g)
+			writeBack()
+
+			if err := s.Step([]G.ValueGrad{wg}); err != nil {
+				t.Fatalf("%v: %+v", c.solver, err)
+			}
+		}
+		w, _ := float64s(wg.w)
+		for _, v := range w {
+			if math.Abs(v-3) > 0.05 {
+				t.Errorf("%v (%v): Expected the weights to be close to 3. Got %v", c.solver, c.dt, w)
+			}
+		}
+		g, _ := float64s(wg.g)
+		assert.Equal(t, []float64{0, 0}, g, "%v: the gradients should be zeroed", c.solver)
+		assert.Equal(t, 500, s.steps)
+	}
+
+	if _, err := ParseSolverType("adam"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseSolverType("lbfgs"); err == nil {
+		t.Error("Expected an error parsing an unknown solver")
+	}
+}
+
+func TestSolver_Clip(t *testing.T) {
+	conf := DefaultNNConfig
+	conf.Solver = SGD
+	conf.Momentum = 0
+	conf.AdaAlpha = 1
+	conf.Reg = 0
+	conf.ClipNorm = 1
+	s, err := newSolver(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := weightGrad{
+		w: tensor.New(tensor.WithBacking([]float64{0, 0})),
+		g: tensor.New(tensor.WithBacking([]float64{3, 4})),
+	}
+	if err := s.Step([]G.ValueGrad{wg}); err != nil {
+		t.Fatal(err)
+	}
+	w, _ := float64s(wg.w)
+	assert.InDeltaSlice(t, []float64{-0.6, -0.8}, w, 1e-12)
+
+	s.clip = 0.5
+	copy(wg.g.Data().([]float64), []float64{3, 0.1})
+	if err := s.Step([]G.ValueGrad{wg}); err != nil {
+		t.Fatal(err)
+	}
+	w, _ = float64s(wg.w)
+	// scaled to (0.9995, 0.0333), then clipped to (0.5, 0.0333)
+	assert.InDeltaSlice(t, []float64{-1.1, -0.8 - 0.1/math.Sqrt(9.01)}, w, 1e-12)
+}
+
+func TestSchedule(t *testing.T) {
+	var s Schedule
+	assert.Equal(t, 1.0, s.rate(0))
+	assert.Equal(t, 1.0, s.rate(1000))
+
+	s = Schedule{Decay: StepDecay, Steps: 10, Factor: 0.5, Warmup: 4}
+	assert.Equal(t, 0.25, s.rate(0))
+	assert.Equal(t, 1.0, s.rate(3))
+	assert.Equal(t, 1.0, s.rate(13))
+	assert.Equal(t, 0.5, s.rate(14))
+	assert.Equal(t, 0.25, s.rate(24))
+
+	s = Schedule{Decay: CosineDecay, Steps: 10, Factor: 0.1}
+	assert.Equal(t, 1.0, s.rate(0))
+	assert.InDelta(t, 0.55, s.rate(5), 1e-12)
+	assert.Equal(t, 0.1, s.rate(10))
+	assert.Equal(t, 0.1, s.rate(100))
+
+	d, err := ParseDecayType("cosine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, CosineDecay, d)
+}
+
+func TestNNConfig_Solver(t *testing.T) {
+	conf := DefaultNNConfig
+	conf.Solver = Adam
+	conf.Momentum = 0.8
+	conf.Decay = 0.99
+	conf.Schedule = Schedule{Decay: CosineDecay, Warmup: 100, Steps: 1000, Factor: 0.01}
+	conf.Clip = 5
+	conf.ClipNorm = 10
+
+	b, err := conf.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf2 NNConfig
+	if err = conf2.GobDecode(b); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, conf, conf2)
+
+	// a model saved before the solver could be configured was trained with AdaGrad
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+	old := []interface{}{conf.BatchSize, conf.Dropout, conf.AdaEps, conf.AdaAlpha, conf.Reg, conf.HiddenSize, conf.EmbeddingSize,
+		conf.NumPrecomputed, conf.EvalPerIteration, conf.ClearGradientsPerIteration, byte(0), ArcEager, false, ""}
+	for _, v := range old {
+		if err = encoder.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var conf3 NNConfig
+	if err = conf3.GobDecode(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ArcEager, conf3.TransitionSystem)
+	assert.Equal(t, AdaGrad, conf3.Solver)
+	assert.Equal(t, DefaultNNConfig.Momentum, conf3.Momentum)
+	assert.Equal(t, Schedule{}, conf3.Schedule)
+
+	// but a model that is cut off in the middle of a field is not loaded
+	if err = conf3.GobDecode(b[:len(b)-1]); err == nil {
+		t.Error("Expected an error when decoding a truncated config")
+	}
+
+	// the state of the solver survives a round trip
+	s, _ := newSolver(conf)
+	b, err = s.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, _ := newSolver(conf)
+	if err = s2.GobDecode(b); err != nil {
+		t.Fatal(err)
+	}
+	s.steps = 2
+	s.cache = [][][]float64{{{1, 2}, {3, 4}}, nil}
+	if b, err = s.GobEncode(); err != nil {
+		t.Fatal(err)
+	}
+	if err = s2.GobDecode(b); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, s, s2)
+}
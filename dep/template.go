@@ -0,0 +1,397 @@
+package dep
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/corpus"
+	"github.com/pkg/errors"
+)
+
+/*
+Feature templates allow the features of the parser to be defined as data, instead of being fixed by the package.
+
+A feature template extracts an attribute of the word at a position in the configuration. The positions are written as:
+	s0       - the top of the stack. s1 is the word below it, and so on
+	b0       - the front of the buffer. b1 is the word after it, and so on
+	s0.l1    - the leftmost child of s0. s0.l2 is the second leftmost child
+	s0.r1    - the rightmost child of s0. s0.r2 is the second rightmost child
+	s0.l1.l1 - children can be followed to any depth. This is the leftmost child of the leftmost child of s0
+
+Feature templates can also be written as text, one template per line (or separated by spaces):
+	word[s0]
+	pos[b1]
+	label[s0.l1]
+	suffix:3[s0]
+
+The attributes are:
+	word    - the word
+	lemma   - the lemma
+	pos     - the POSTag
+	label   - the label of the arc from the word's head
+	cluster - the brown cluster. cluster:N uses the first N bits of the cluster
+	shape   - the shape of the word
+	suffix  - suffix:N is the last N characters of the lowercased word
+
+Words and lemmas share the word embeddings, and are looked up in the corpus. POSTags and labels have their own embeddings.
+Clusters, shapes and suffixes are hashed into a fixed number (hashBuckets) of embeddings.
+*/
+
+// Attribute is an attribute of a word that a feature template extracts
+type Attribute byte
+
+const (
+	WordAttr Attribute = iota
+	LemmaAttr
+	POSTagAttr
+	LabelAttr
+	ClusterAttr
+	ShapeAttr
+	SuffixAttr
+
+	MAXATTRIBUTE
+)
+
+var attributeNames = [MAXATTRIBUTE]string{"word", "lemma", "pos", "label", "cluster", "shape", "suffix"}
+
+func (a Attribute) String() string {
+	if a >= MAXATTRIBUTE {
+		return fmt.Sprintf("Attribute(%d)", a)
+	}
+	return attributeNames[a]
+}
+
+// embedding returns the kind of embedding the values of the attribute are looked up in
+func (a Attribute) embedding() embedding {
+	switch a {
+	case WordAttr, LemmaAttr:
+		return wordEmbedding
+	case POSTagAttr:
+		return tagEmbedding
+	case LabelAttr:
+		return labelEmbedding
+	default:
+		return hashedEmbedding
+	}
+}
+
+// embedding is a kind of embedding in the neural network
+type embedding byte
+
+const (
+	wordEmbedding embedding = iota
+	tagEmbedding
+	labelEmbedding
+	hashedEmbedding
+
+	MAXEMBEDDING
+)
+
+// hashBuckets is the number of embeddings that the values of clusters, shapes and suffixes are hashed into
+const hashBuckets = 1 << 14
+
+// Child is a step from a word to one of its children. N counts from the outermost child, starting at 1.
+type Child struct {
+	Right bool // if false, the children are counted from the left
+	N     int
+}
+
+func (c Child) String() string {
+	if c.Right {
+		return fmt.Sprintf("r%d", c.N)
+	}
+	return fmt.Sprintf("l%d", c.N)
+}
+
+// Position is a word in the configuration: a word on the stack or in the buffer, or a descendant of such a word.
+type Position struct {
+	Buffer   bool // if false, the word is on the stack
+	Index    int  // 0 is the top of the stack, or the front of the buffer
+	Children []Child
+}
+
+func (p Position) String() string {
+	var buf bytes.Buffer
+	if p.Buffer {
+		fmt.Fprintf(&buf, "b%d", p.Index)
+	} else {
+		fmt.Fprintf(&buf, "s%d", p.Index)
+	}
+	for _, c := range p.Children {
+		fmt.Fprintf(&buf, ".%v", c)
+	}
+	return buf.String()
+}
+
+// word finds the word at the position in the configuration. If there is no such word, DOES_NOT_EXIST is returned
+func (p Position) word(c *configuration) head {
+	var k head
+	if p.Buffer {
+		k = c.bufferValue(p.Index)
+	} else {
+		k = c.stackValue(p.Index)
+	}
+
+	for _, child := range p.Children {
+		if child.Right {
+			k = c.rc(k, head(child.N))
+		} else {
+			k = c.lc(k, head(child.N))
+		}
+	}
+	return k
+}
+
+// FeatureTemplate is a template for a feature. It describes the attribute of the word at a position in the configuration.
+// N is only used by the cluster and suffix attributes.
+type FeatureTemplate struct {
+	Position
+	Attribute
+	N int
+}
+
+func (ft FeatureTemplate) String() string {
+	if ft.N > 0 {
+		return fmt.Sprintf("%v:%d[%v]", ft.Attribute, ft.N, ft.Position)
+	}
+	return fmt.Sprintf("%v[%v]", ft.Attribute, ft.Position)
+}
+
+// defaultFeatureTemplates are the features described by Chen and Manning in "A Fast and Accurate Dependency Parser using Neural Networks" (2014).
+// They are in the same order as the features the parser always used, so models trained before feature templates were introduced can still be loaded.
+const defaultFeatureTemplates = `word[s2] word[s1] word[s0] word[b0] word[b1] word[b2]
+word[s0.l1] word[s0.r1] word[s0.l2] word[s0.r2] word[s0.l1.l1] word[s0.r1.r1]
+word[s1.l1] word[s1.r1] word[s1.l2] word[s1.r2] word[s1.l1.l1] word[s1.r1.r1]
+pos[s2] pos[s1] pos[s0] pos[b0] pos[b1] pos[b2]
+pos[s0.l1] pos[s0.r1] pos[s0.l2] pos[s0.r2] pos[s0.l1.l1] pos[s0.r1.r1]
+pos[s1.l1] pos[s1.r1] pos[s1.l2] pos[s1.r2] pos[s1.l1.l1] pos[s1.r1.r1]
+label[s0.l1] label[s0.r1] label[s0.l2] label[s0.r2] label[s0.l1.l1] label[s0.r1.r1]
+label[s1.l1] label[s1.r1] label[s1.l2] label[s1.r2] label[s1.l1.l1] label[s1.r1.r1]
+`
+
+// DefaultFeatureTemplates returns the feature templates that describe the features the parser uses when no templates are provided.
+// It is a good starting point for experimenting with feature templates.
+func DefaultFeatureTemplates() []FeatureTemplate {
+	fts, err := ParseFeatureTemplates(defaultFeatureTemplates)
+	if err != nil {
+		panic(err)
+	}
+	return fts
+}
+
+// ParseFeatureTemplates parses feature templates written in text. Lines starting with # are comments.
+func ParseFeatureTemplates(s string) ([]FeatureTemplate, error) {
+	var retVal []FeatureTemplate
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, field := range strings.Fields(line) {
+			ft, err := parseFeatureTemplate(field)
+			if err != nil {
+				return nil, err
+			}
+			retVal = append(retVal, ft)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+func parseFeatureTemplate(s string) (ft FeatureTemplate, err error) {
+	open := strings.Index(s, "[")
+	if open < 0 || !strings.HasSuffix(s, "]") {
+		return ft, errors.Errorf("Unable to parse feature template %q. Expected attribute[position]", s)
+	}
+
+	name := s[:open]
+	if colon := strings.Index(name, ":"); colon >= 0 {
+		if ft.N, err = strconv.Atoi(name[colon+1:]); err != nil || ft.N <= 0 {
+			return ft, errors.Errorf("Unable to parse length of feature template %q", s)
+		}
+		name = name[:colon]
+	}
+
+	ft.Attribute = MAXATTRIBUTE
+	for i, n := range attributeNames {
+		if n == name {
+			ft.Attribute = Attribute(i)
+		}
+	}
+	switch ft.Attribute {
+	case MAXATTRIBUTE:
+		return ft, errors.Errorf("Unknown attribute %q in feature template %q", name, s)
+	case SuffixAttr:
+		if ft.N == 0 {
+			return ft, errors.Errorf("%v requires a length in feature template %q", ft.Attribute, s)
+		}
+	}
+
+	if ft.Position, err = parsePosition(s[open+1 : len(s)-1]); err != nil {
+		return ft, errors.Wrapf(err, "Unable to parse feature template %q", s)
+	}
+	return ft, nil
+}
+
+func parsePosition(s string) (p Position, err error) {
+	steps := strings.Split(s, ".")
+	if len(steps[0]) < 2 {
+		return p, errors.Errorf("Expected a position on the stack (s0, s1...) or the buffer (b0, b1...). Got %q", steps[0])
+	}
+
+	switch steps[0][0] {
+	case 's':
+	case 'b':
+		p.Buffer = true
+	default:
+		return p, errors.Errorf("Expected a position on the stack (s0, s1...) or the buffer (b0, b1...). Got %q", steps[0])
+	}
+	if p.Index, err = strconv.Atoi(steps[0][1:]); err != nil || p.Index < 0 {
+		return p, errors.Errorf("Unable to parse index of %q", steps[0])
+	}
+
+	for _, step := range steps[1:] {
+		var c Child
+		if len(step) < 2 {
+			return p, errors.Errorf("Expected a child (l1, r1...). Got %q", step)
+		}
+		switch step[0] {
+		case 'l':
+		case 'r':
+			c.Right = true
+		default:
+			return p, errors.Errorf("Expected a child (l1, r1...). Got %q", step)
+		}
+		if c.N, err = strconv.Atoi(step[1:]); err != nil || c.N <= 0 {
+			return p, errors.Errorf("Unable to parse child %q", step)
+		}
+		p.Children = append(p.Children, c)
+	}
+	return p, nil
+}
+
+// formatFeatureTemplates writes the feature templates as text, such that ParseFeatureTemplates parses them back
+func formatFeatureTemplates(fts []FeatureTemplate) string {
+	var buf bytes.Buffer
+	for i, ft := range fts {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(ft.String())
+	}
+	return buf.String()
+}
+
+// featureSet is a list of feature templates, grouped by the embeddings they use.
+// The features are extracted in that order: word features first, followed by POSTag, label and hashed features.
+type featureSet struct {
+	templates [MAXEMBEDDING][]FeatureTemplate
+}
+
+var defaultFeatures *featureSet
+
+func newFeatureSet(fts []FeatureTemplate) (*featureSet, error) {
+	if len(fts) == 0 {
+		return nil, errors.Errorf("A feature set needs at least one feature template")
+	}
+
+	fs := new(featureSet)
+	for _, ft := range fts {
+		if ft.Attribute >= MAXATTRIBUTE {
+			return nil, errors.Errorf("Unknown attribute in feature template %v", ft)
+		}
+		e := ft.Attribute.embedding()
+		fs.templates[e] = append(fs.templates[e], ft)
+	}
+	return fs, nil
+}
+
+// count returns the number of features that use the kind of embedding
+func (fs *featureSet) count(e embedding) int { return len(fs.templates[e]) }
+
+// size returns the number of features
+func (fs *featureSet) size() int {
+	var retVal int
+	for _, fts := range fs.templates {
+		retVal += len(fts)
+	}
+	return retVal
+}
+
+// extract extracts the IDs to pass into the neural network. These IDs are used in the network to construct the input layers.
+//
+// The IDs of words are offset by wordFeatsStartAt, and the IDs of labels by labelFeatsStartAt.
+func (fs *featureSet) extract(c *configuration, dict *corpus.Corpus) []int {
+	unknownID, _ := dict.Id("-UNKNOWN-")
+
+	features := make([]int, 0, fs.size())
+	for _, fts := range fs.templates {
+		for _, ft := range fts {
+			index := ft.word(c)
+			a := c.annotation(index)
+
+			var id int
+			switch ft.Attribute {
+			case WordAttr, LemmaAttr:
+				w := a.Value
+				if ft.Attribute == LemmaAttr {
+					w = a.Lemma
+				}
+				var ok bool
+				if id, ok = dict.Id(w); !ok {
+					id = unknownID
+				}
+				id += wordFeatsStartAt
+			case POSTagAttr:
+				id = int(a.POSTag)
+			case LabelAttr:
+				id = int(c.label(index)) + labelFeatsStartAt
+			default:
+				id = hashAttribute(a, ft.Attribute, ft.N)
+			}
+			features = append(features, id)
+		}
+	}
+	return features
+}
+
+// hashAttribute hashes the value of the attribute of the annotation into one of hashBuckets buckets
+func hashAttribute(a *lingo.Annotation, attr Attribute, n int) int {
+	var val string
+	switch attr {
+	case ClusterAttr:
+		val = strconv.FormatInt(int64(a.Cluster), 2)
+		if n > 0 && len(val) > n {
+			val = val[:n]
+		}
+	case ShapeAttr:
+		val = string(a.Shape)
+	case SuffixAttr:
+		runes := []rune(a.Lowered)
+		if len(runes) >= n {
+			val = string(runes[len(runes)-n:])
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte{byte(attr), byte(n)})
+	h.Write([]byte(val))
+	return int(h.Sum32() % hashBuckets)
+}
+
+func init() {
+	var err error
+	if defaultFeatures, err = newFeatureSet(DefaultFeatureTemplates()); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,164 @@
+package dep
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+)
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestParseFeatureTemplates(t *testing.T) {
+	fts, err := ParseFeatureTemplates(`# comment
+word[s0] lemma[b1]
+pos[s1.l1.r2]
+label[s0.r1] cluster:4[b0] shape[s2] suffix:3[s0.l2]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []FeatureTemplate{
+		{Position{false, 0, nil}, WordAttr, 0},
+		{Position{true, 1, nil}, LemmaAttr, 0},
+		{Position{false, 1, []Child{{false, 1}, {true, 2}}}, POSTagAttr, 0},
+		{Position{false, 0, []Child{{true, 1}}}, LabelAttr, 0},
+		{Position{true, 0, nil}, ClusterAttr, 4},
+		{Position{false, 2, nil}, ShapeAttr, 0},
+		{Position{false, 0, []Child{{false, 2}}}, SuffixAttr, 3},
+	}, fts)
+
+	// formatting and parsing again gives the same templates
+	fts2, err := ParseFeatureTemplates(formatFeatureTemplates(fts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, fts, fts2)
+
+	for _, bad := range []string{"word", "word[]", "foo[s0]", "word[x0]", "word[s]", "word[s-1]", "word[s0.x1]", "word[s0.l0]", "suffix[s0]", "suffix:0[s0]"} {
+		if _, err := ParseFeatureTemplates(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+
+	assert.Equal(t, 48, len(DefaultFeatureTemplates()))
+	assert.Equal(t, POS_OFFSET, defaultFeatures.count(wordEmbedding))
+	assert.Equal(t, DEP_OFFSET, defaultFeatures.count(wordEmbedding)+defaultFeatures.count(tagEmbedding))
+}
+
+func TestPosition_word(t *testing.T) {
+	st := allSentences()[0]
+	s := st.AnnotatedSentence(dummyFix{})
+	d := s.Dependency()
+	c := newConfiguration(s, true)
+
+	// Guerrillas threatened to assassinate ...
+	// shift twice, and attach Guerrillas to threatened
+	c.apply(c.oracle(d))
+	c.apply(c.oracle(d))
+	c.apply(c.oracle(d))
+
+	pos := func(s string) Position {
+		p, err := parsePosition(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	assert.Equal(t, head(2), pos("s0").word(c))
+	assert.Equal(t, head(0), pos("s1").word(c))
+	assert.Equal(t, DOES_NOT_EXIST, pos("s2").word(c))
+	assert.Equal(t, head(3), pos("b0").word(c))
+	assert.Equal(t, head(1), pos("s0.l1").word(c))
+	assert.Equal(t, DOES_NOT_EXIST, pos("s0.r1").word(c))
+	assert.Equal(t, DOES_NOT_EXIST, pos("s0.l1.l1").word(c))
+}
+
+func TestTrainer_FeatureTemplates(t *testing.T) {
+	fts, err := ParseFeatureTemplates("word[s0] word[b0] lemma[s1] pos[s0] suffix:3[s0] suffix:3[b0] shape[b0] cluster:2[s0.l1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.Dropout = 0
+	conf.Features = fts
+	trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts))
+	if err := trainer.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	nn := trainer.nn
+	assert.Equal(t, 3, len(nn.x_wSelW))
+	assert.Equal(t, 1, len(nn.x_tSelT))
+	assert.Equal(t, 0, len(nn.x_lSelL))
+	assert.Equal(t, 4, len(nn.x_sSelS))
+	if nn.e_l != nil || nn.w1_l != nil {
+		t.Error("Expected no label embeddings when no feature uses labels")
+	}
+
+	if err := trainer.Train(1); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// the precomputed network agrees with the expression graph
+	s := sts[0].AnnotatedSentence(dummyFix{})
+	c := newConfiguration(s, true)
+	for !c.isTerminal() {
+		features := nn.fs.extract(c, nn.dict)
+		if len(features) != len(fts) {
+			t.Fatalf("Expected %d features. Got %d", len(fts), len(features))
+		}
+		want, err := nn.predGraph(features)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		sc, err := nn.newScratch()
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		sc.pred(features)
+		for i := range want {
+			if diff := want[i] - sc.scores[i]; diff > 1e-8 || diff < -1e-8 {
+				t.Fatalf("Score %d differs. Want %v. Got %v", i, want[i], sc.scores[i])
+			}
+		}
+		c.apply(c.oracle(s.Dependency()))
+	}
+
+	// the feature templates are saved with the model
+	var buf bytes.Buffer
+	if err := trainer.Model.SaveWriter(nopCloser{&buf}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	m, err := LoadReader(ioutil.NopCloser(&buf))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(t, fts, m.FeatureTemplates())
+	if !G.ValueEq(nn.e_s.Value(), m.nn.e_s.Value()) {
+		t.Error("Expected the hashed embeddings to be the same")
+	}
+	if _, err := New(m).Parse(sts[1].AnnotatedSentence(dummyFix{})); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestHashAttribute(t *testing.T) {
+	a := lingo.NewAnnotation()
+	a.Lowered = "running"
+	b := lingo.NewAnnotation()
+	b.Lowered = "jumping"
+
+	assert.Equal(t, hashAttribute(a, SuffixAttr, 3), hashAttribute(b, SuffixAttr, 3))
+	assert.NotEqual(t, hashAttribute(a, SuffixAttr, 4), hashAttribute(b, SuffixAttr, 4))
+	if h := hashAttribute(a, ShapeAttr, 0); h < 0 || h >= hashBuckets {
+		t.Errorf("Expected the hash to be within [0, %d). Got %d", hashBuckets, h)
+	}
+}
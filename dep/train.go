@@ -2,8 +2,10 @@ package dep
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/chewxy/lingo"
 	"github.com/chewxy/lingo/corpus"
@@ -49,6 +51,28 @@ func WithConfig(conf NNConfig) TrainerConsOpt {
 	return f
 }
 
+// WithDynamicOracle sets up a *Trainer to train with a dynamic oracle. The examples are made afresh every epoch by parsing
+// the training set with the model being trained. How often the parser follows its own (possibly wrong) predictions
+// is determined by the exploration schedule, so the model learns to recover from its mistakes.
+//
+// ArcStandard and ArcEager have dynamic oracles. ArcSwap doesn't, so Train returns an error if this option is used with it.
+func WithDynamicOracle(schedule ExplorationSchedule) TrainerConsOpt {
+	f := func(t *Trainer) {
+		t.exploration = schedule
+	}
+	return f
+}
+
+// WithSeed sets up a *Trainer to draw all its random numbers from a source seeded by the given seed: the initial weights, the dropout masks,
+// the shuffling of the training examples, the exploration of the dynamic oracle and the jackknifing taggers.
+// Training with the same data, config and seed gives the same model. By default the seed is the time the Trainer is created.
+func WithSeed(seed int64) TrainerConsOpt {
+	f := func(t *Trainer) {
+		t.src.Seed(seed)
+	}
+	return f
+}
+
 // WithLemmatizer sets the lemmatizer option on the Trainer
 func WithLemmatizer(l lingo.Lemmatizer) TrainerConsOpt {
 	f := func(t *Trainer) {
@@ -119,6 +143,20 @@ type Trainer struct {
 	PassDirect  bool   // Pass on the costs directly to the cost channel? If false, an average will be used
 	SaveBest    string // SaveBest is the filename that will be saved. If it's empty then the best-while-training will not be saved
 
+	exploration ExplorationSchedule // if not nil, a dynamic oracle is used
+	jackknife   *jackknife          // if not nil, the gold POS tags of the training set are replaced with predicted ones
+	pretrained  *pretrained         // if not nil, the word embeddings are initialized from pretrained vectors
+
+	// training state. It is saved in checkpoints
+	src      *countingSource
+	rng      *rand.Rand
+	epoch    int       // number of epochs trained
+	examples []example // the examples, in the order they will be trained on. Only kept when training with the static oracle
+	best     Performance
+
+	checkpoint      string // if not empty, a checkpoint is saved to this file every checkpointEvery epochs
+	checkpointEvery int
+
 	// fixer
 	l lingo.Lemmatizer
 	s lingo.Stemmer
@@ -143,9 +181,17 @@ func NewTrainer(opts ...TrainerConsOpt) *Trainer {
 	t.nn.transitions = transitions
 	t.nn.dict = KnownWords
 
+	t.src = newCountingSource(time.Now().UnixNano())
+	t.rng = rand.New(t.src)
+
 	for _, opt := range opts {
 		opt(t)
 	}
+
+	// the transitions are defined by the transition system in the config
+	t.ts = t.nn.transitionTable()
+	t.nn.transitions = t.ts
+	t.nn.rng = t.rng
 	return t
 }
 
@@ -192,12 +238,46 @@ func (t *Trainer) Perf() <-chan Performance {
 	return t.perf
 }
 
+// Epoch returns the number of epochs the model has been trained for, including the epochs trained before the training was resumed.
+func (t *Trainer) Epoch() int { return t.epoch }
+
 /* Methods */
 
-// Init initializes the DependencyParser with a corpus and a neural network config
+// Init initializes the DependencyParser with a corpus and a neural network config.
+// If the Trainer was created with WithJackknifing, the training set is tagged here.
+// If it was created with WithPretrainedEmbeddings, the pretrained embeddings are loaded here.
 func (t *Trainer) Init() (err error) {
 	f := func() {
-		err = t.nn.init()
+		var vecs map[int][]float64
+		if t.pretrained != nil {
+			if t.nn.dict == nil {
+				err = errors.Errorf("No Corpus Provided to the Neural Network. Cannot load pretrained embeddings")
+				return
+			}
+			if vecs, err = t.pretrained.load(t.nn.dict, t.nn.EmbeddingSize); err != nil {
+				return
+			}
+		}
+
+		if err = t.nn.init(); err != nil {
+			return
+		}
+
+		if t.pretrained != nil {
+			if t.nn.e_w == nil {
+				err = errors.Errorf("Cannot use pretrained embeddings without any word features")
+				return
+			}
+			if err = setRows(t.nn.e_w.Value(), vecs); err != nil {
+				return
+			}
+			if t.pretrained.freeze {
+				t.nn.frozen = vecs
+			}
+		}
+		if t.jackknife != nil {
+			t.trainingSet, err = t.jackknife.tag(t.trainingSet, t.rng)
+		}
 	}
 	t.once.Do(f)
 	return
@@ -226,21 +306,28 @@ func (t *Trainer) train(epochs int) error {
 
 	var epochChan chan struct{}
 	if t.cost != nil {
+		var stop func()
+		epochChan, stop = t.handleCosts()
 		defer func() {
+			stop() // the costs must be all handled before the channel is closed
 			close(t.cost)
 			t.cost = nil
 		}()
-
-		epochChan = t.handleCosts()
-		if epochChan != nil {
-			defer close(epochChan)
-		}
 	}
 
-	examples := makeExamples(t.trainingSet, t.nn.NNConfig, t.nn.dict, t.ts, t)
+	if t.exploration == nil && t.examples == nil {
+		t.examples = makeExamples(t.trainingSet, t.nn.NNConfig, t.nn.fs, t.nn.dict, t.ts, t)
+	}
 
 	for e := 0; e < epochs; e++ {
-		if err := t.nn.train(examples); err != nil {
+		if t.exploration != nil {
+			var err error
+			if t.examples, err = t.makeDynamicExamples(t.exploration(t.epoch)); err != nil {
+				return err
+			}
+		}
+
+		if err := t.nn.train(t.examples); err != nil {
 			return err
 		}
 
@@ -248,7 +335,9 @@ func (t *Trainer) train(epochs int) error {
 			epochChan <- struct{}{}
 		}
 
-		shuffleExamples(examples)
+		if err := t.endEpoch(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -264,35 +353,44 @@ func (t *Trainer) crossValidateTrain(epochs int) error {
 
 	var epochChan chan struct{}
 	if t.cost != nil {
+		var stop func()
+		epochChan, stop = t.handleCosts()
 		defer func() {
+			stop() // the costs must be all handled before the channel is closed
 			close(t.cost)
 			t.cost = nil
 		}()
-
-		epochChan = t.handleCosts()
-		if epochChan != nil {
-			defer close(epochChan)
-		}
 	}
-	examples := makeExamples(t.trainingSet, t.nn.NNConfig, t.nn.dict, t.ts, t)
+	if t.exploration == nil && t.examples == nil {
+		t.examples = makeExamples(t.trainingSet, t.nn.NNConfig, t.nn.fs, t.nn.dict, t.ts, t)
+	}
 
-	var best Performance
 	for e := 0; e < epochs; e++ {
-		if err := t.nn.train(examples); err != nil {
+		if t.exploration != nil {
+			var err error
+			if t.examples, err = t.makeDynamicExamples(t.exploration(t.epoch)); err != nil {
+				return err
+			}
+		}
+
+		if err := t.nn.train(t.examples); err != nil {
 			return err
 		}
 
-		if t.EvalPerIter > 0 && e%t.EvalPerIter == 0 || e == epochs-1 {
-			perf := t.crossValidate(t.crossValSet)
+		if t.EvalPerIter > 0 && t.epoch%t.EvalPerIter == 0 || e == epochs-1 {
+			perf, err := t.crossValidate(t.crossValSet)
+			if err != nil {
+				return err
+			}
+			perf.Iter = t.epoch
 
 			// if there is a channel to report back the performance, send it down
 			if t.perf != nil {
-				perf.Iter = e
 				t.perf <- perf
 			}
 
-			if perf.UAS > best.UAS {
-				best = perf
+			if perf.UAS > t.best.UAS {
+				t.best = perf
 
 				if t.SaveBest != "" {
 					f, err := os.Create(t.SaveBest)
@@ -310,7 +408,20 @@ func (t *Trainer) crossValidateTrain(epochs int) error {
 			epochChan <- struct{}{}
 		}
 
-		shuffleExamples(examples)
+		if err := t.endEpoch(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endEpoch shuffles the examples for the next epoch, and saves a checkpoint if one is due.
+func (t *Trainer) endEpoch() error {
+	shuffleExamples(t.examples, t.rng)
+	t.epoch++
+
+	if t.checkpoint != "" && t.checkpointEvery > 0 && t.epoch%t.checkpointEvery == 0 {
+		return t.SaveCheckpoint(t.checkpoint)
 	}
 	return nil
 }
@@ -326,6 +437,18 @@ func (t *Trainer) pretrainCheck() error {
 		return errors.Errorf("Cannot train with no training data set")
 	}
 
+	if t.exploration != nil && !t.nn.TransitionSystem.hasDynamicOracle() {
+		return errors.Errorf("%v has no dynamic oracle. Cannot train with a dynamic oracle", t.nn.TransitionSystem)
+	}
+
+	if t.nn.JointTagging && t.nn.TransitionSystem == ArcEager {
+		return errors.Errorf("Joint tagging is not supported by %v, which also pushes words onto the stack with Right", t.nn.TransitionSystem)
+	}
+
+	if t.nn.JointTagging && t.jackknife != nil {
+		return errors.Errorf("Cannot train with jackknifed POS tags when tagging jointly")
+	}
+
 	return nil
 }
 
@@ -333,21 +456,34 @@ func (t *Trainer) pretrainCheck() error {
 //		1. pass: directly passes on the costs (which may come from multiple batches in an epoch)
 //		2. mean: calculates the mean of the costs and passes it on into d.cost
 //
-// If d.cost is nil, it simply returns. This method should be called after a check that d.cost is not nil
-func (t *Trainer) handleCosts() (epochChan chan struct{}) {
+// If d.cost is nil, it simply returns. This method should be called after a check that d.cost is not nil.
+// The returned stop function stops the handling of costs. It returns only after the last cost has been sent down d.cost
+func (t *Trainer) handleCosts() (epochChan chan struct{}, stop func()) {
 	nncost := t.nn.costProgress()
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	stop = func() {
+		close(quit)
+		<-done
+	}
 
 	if t.PassDirect {
 		go func() {
-			for cost := range nncost {
-				switch c := cost.Data().(type) {
-				case float32:
-					t.cost <- float64(c)
-				case float64:
-					t.cost <- c
-				default:
-					// this should NEVER happen
-					panic(fmt.Sprintf("Unhandled cost type %T", c))
+			defer close(done)
+			for {
+				select {
+				case cost := <-nncost:
+					switch c := cost.Data().(type) {
+					case float32:
+						t.cost <- float64(c)
+					case float64:
+						t.cost <- c
+					default:
+						// this should NEVER happen
+						panic(fmt.Sprintf("Unhandled cost type %T", c))
+					}
+				case <-quit:
+					return
 				}
 			}
 		}()
@@ -356,6 +492,7 @@ func (t *Trainer) handleCosts() (epochChan chan struct{}) {
 
 		// it collects the costs until the epoch chan signals that an epoch is done. Then the cost is averaged and sent down the d.cost channel
 		go func(epochChan chan struct{}) {
+			defer close(done)
 			var collected []float64
 			for {
 				select {
@@ -381,6 +518,8 @@ func (t *Trainer) handleCosts() (epochChan chan struct{}) {
 
 					t.cost <- avg
 					collected = collected[:0]
+				case <-quit:
+					return
 				}
 			}
 		}(epochChan)
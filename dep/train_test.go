@@ -233,3 +233,31 @@ avgCosts:
 		t.Errorf("Costs should be reducing")
 	}
 }
+
+func TestTrainer_WithSeed(t *testing.T) {
+	sts := allSentences()
+	conf := DefaultNNConfig
+	conf.BatchSize = 90
+	conf.Dropout = 0.2
+
+	train := func(seed int64) *neuralnetwork2 {
+		trainer := NewTrainer(WithGeneratedCorpus(sts...), WithConfig(conf), WithTrainingSet(sts), WithJackknifing(2, 2), WithSeed(seed))
+		if err := trainer.Init(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if err := trainer.Train(2); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		return trainer.nn
+	}
+
+	a, b, c := train(1337), train(1337), train(1)
+	for i, n := range a.serialized() {
+		if !G.ValueEq(n.Value(), b.serialized()[i].Value()) {
+			t.Errorf("Expected %v to be the same when trained with the same seed", n.Name())
+		}
+		if G.ValueEq(n.Value(), c.serialized()[i].Value()) {
+			t.Errorf("Expected %v to be different when trained with a different seed", n.Name())
+		}
+	}
+}
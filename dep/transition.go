@@ -6,16 +6,20 @@ import (
 	"github.com/chewxy/lingo"
 )
 
-// transition is a tuple of Move and label
+// transition is a tuple of Move and label. When parsing jointly with POS tagging, Shift also carries the POSTag of the shifted word.
+// Otherwise the POSTag is always lingo.X.
 type transition struct {
 	Move
 	lingo.DependencyType
+	lingo.POSTag
 }
 
-var transitions []transition
+var transitions []transition // the transitions of the arc-standard transition system
 var MAXTRANSITION int
 
-func buildTransitions(labels []lingo.DependencyType) []transition {
+var systemTransitions [MAXTRANSITIONSYSTEM][]transition
+
+func buildTransitions(moves []Move, labels []lingo.DependencyType) []transition {
 	ts := make([]transition, 0)
 	// for _, l := range labels {
 	// 	if l == lingo.NoDepType {
@@ -36,12 +40,13 @@ func buildTransitions(labels []lingo.DependencyType) []transition {
 
 	// ts = append(ts, transition{Shift, lingo.NoDepType})
 
-	for _, m := range ALLMOVES {
+	for _, m := range moves {
+		unlabelled := m == Shift || m == Reduce || m == Swap
 		for _, l := range labels {
-			if (m == Shift && l != lingo.NoDepType) || (m != Shift && l == lingo.NoDepType) {
+			if (unlabelled && l != lingo.NoDepType) || (!unlabelled && l == lingo.NoDepType) {
 				continue
 			}
-			t := transition{m, l}
+			t := transition{m, l, lingo.X}
 			ts = append(ts, t)
 		}
 	}
@@ -49,6 +54,9 @@ func buildTransitions(labels []lingo.DependencyType) []transition {
 }
 
 func (t transition) String() string {
+	if t.POSTag != lingo.X {
+		return fmt.Sprintf("(%s, %s, %s)", t.Move, t.DependencyType, t.POSTag)
+	}
 	return fmt.Sprintf("(%s, %s)", t.Move, t.DependencyType)
 }
 
@@ -69,6 +77,10 @@ func init() {
 		lbls[i] = lingo.DependencyType(i)
 	}
 
-	transitions = buildTransitions(lbls)
+	for ts := TransitionSystem(0); ts < MAXTRANSITIONSYSTEM; ts++ {
+		systemTransitions[ts] = buildTransitions(ts.moves(), lbls)
+	}
+
+	transitions = systemTransitions[ArcStandard]
 	MAXTRANSITION = len(transitions)
 }
@@ -0,0 +1,161 @@
+package dep
+
+import (
+	"fmt"
+
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+)
+
+// TransitionSystem is the set of moves the parser uses to build a dependency tree, and the rules of when the moves can be applied.
+type TransitionSystem byte
+
+const (
+	// ArcStandard builds arcs between the top two words of the stack. It uses Shift, Left and Right.
+	ArcStandard TransitionSystem = iota
+
+	// ArcEager builds arcs between the top of the stack and the front of the buffer, attaching right dependents as early as possible.
+	// It uses Shift, Left, Right and Reduce.
+	ArcEager
+
+	// ArcSwap is the arc-standard system with an additional Swap move, which reorders the words.
+	// Unlike the other systems, it can build non-projective trees.
+	ArcSwap
+
+	MAXTRANSITIONSYSTEM
+)
+
+var transitionSystemNames = [...]string{"ArcStandard", "ArcEager", "ArcSwap"}
+
+func (ts TransitionSystem) String() string {
+	if ts >= MAXTRANSITIONSYSTEM {
+		return fmt.Sprintf("TransitionSystem(%d)", ts)
+	}
+	return transitionSystemNames[ts]
+}
+
+// ParseTransitionSystem returns the TransitionSystem with the given name
+func ParseTransitionSystem(name string) (TransitionSystem, error) {
+	for i, n := range transitionSystemNames {
+		if n == name {
+			return TransitionSystem(i), nil
+		}
+	}
+	return MAXTRANSITIONSYSTEM, errors.Errorf("Unknown transition system %q", name)
+}
+
+// moves returns the moves the transition system uses
+func (ts TransitionSystem) moves() []Move {
+	switch ts {
+	case ArcEager:
+		return []Move{Left, Right, Shift, Reduce}
+	case ArcSwap:
+		return []Move{Left, Right, Shift, Swap}
+	default:
+		return ALLMOVES[:]
+	}
+}
+
+// budget returns the most transitions the transition system needs to parse a sentence of n words.
+// Every word is shifted onto the stack once and popped off it once, and in ArcSwap, each pair of words can be swapped (and shifted again) once.
+func (ts TransitionSystem) budget(n int) int {
+	if ts == ArcSwap {
+		return 2*n + n*(n-1)
+	}
+	return 2 * n
+}
+
+// projective returns true if the transition system can only build projective trees
+func (ts TransitionSystem) projective() bool { return ts != ArcSwap }
+
+// transitions returns the table of transitions (moves and labels) of the transition system. The neural network scores the transitions in this order.
+func (ts TransitionSystem) transitions() []transition {
+	if ts >= MAXTRANSITIONSYSTEM {
+		return transitions
+	}
+	return systemTransitions[ts]
+}
+
+/* dispatch to the transition systems */
+
+// canApply checks if a particular transition can be applied
+func (c *configuration) canApply(t transition) bool {
+	if t.Move == Shift && !c.canTag(t.POSTag) {
+		return false
+	}
+
+	var ok bool
+	switch c.system {
+	case ArcEager:
+		ok = c.arcEagerCanApply(t)
+	case ArcSwap:
+		ok = c.arcSwapCanApply(t)
+	default:
+		ok = c.arcStandardCanApply(t)
+	}
+	return ok && (c.cons == nil || c.cons.allows(c, t))
+}
+
+// effect returns the arc the transition builds (-1s if it doesn't build one), and the word that it pops off the stack for good,
+// which can't take any more dependents (-1 if it doesn't pop a word)
+func (c *configuration) effect(t transition) (h, d, done int) {
+	s0, s1, b0 := int(c.stackValue(0)), int(c.stackValue(1)), int(c.bufferValue(0))
+	switch {
+	case c.system == ArcEager && t.Move == Left:
+		return b0, s0, s0
+	case c.system == ArcEager && t.Move == Right:
+		return s0, b0, -1
+	case c.system == ArcEager && t.Move == Reduce:
+		if c.Head(s0) >= 0 {
+			return -1, -1, s0
+		}
+		switch {
+		case s1 > 0:
+			return s1, s0, s0
+		case c.rootChild() < 0:
+			return 0, s0, s0
+		default:
+			return c.rootChild(), s0, s0
+		}
+	case t.Move == Left:
+		return s0, s1, s1
+	case t.Move == Right:
+		return s1, s0, s0
+	}
+	return -1, -1, -1
+}
+
+// apply applies the transition
+func (c *configuration) apply(t transition) {
+	if t.Move == Shift && c.joint {
+		if b0 := c.bufferValue(0); b0 > 0 {
+			c.Annotation(int(b0)).POSTag = t.POSTag
+		}
+	}
+
+	switch c.system {
+	case ArcEager:
+		c.arcEagerApply(t)
+	case ArcSwap:
+		c.arcSwapApply(t)
+	default:
+		c.arcStandardApply(t)
+	}
+}
+
+// oracle gets the gold transition given the state
+func (c *configuration) oracle(goldParse *lingo.Dependency) (t transition) {
+	switch c.system {
+	case ArcEager:
+		t = c.arcEagerOracle(goldParse)
+	case ArcSwap:
+		t = c.arcSwapOracle(goldParse)
+	default:
+		t = c.arcStandardOracle(goldParse)
+	}
+
+	if t.Move == Shift && c.joint {
+		t.POSTag = goldTag(goldParse.Annotation(int(c.bufferValue(0))).POSTag)
+	}
+	return t
+}
@@ -13,3 +13,14 @@ func maxInt(a, b int) int {
 	}
 	return b
 }
+
+// argmax returns the index of the largest value
+func argmax(a []float64) int {
+	var retVal int
+	for i, v := range a {
+		if v > a[retVal] {
+			retVal = i
+		}
+	}
+	return retVal
+}
@@ -46,3 +46,4 @@ func IsCompound(x DependencyType) bool      { return InDepTypes(x, Compounds) }
 func IsDeterminerRel(x DependencyType) bool { return InDepTypes(x, DeterminerRels) }
 func IsMultiword(x DependencyType) bool     { return InDepTypes(x, MultiWord) }
 func IsQuantifier(x DependencyType) bool    { return InDepTypes(x, QuantifingMods) }
+func IsFunctional(x DependencyType) bool    { return InDepTypes(x, FunctionalRels) }
@@ -92,3 +92,6 @@ var Compounds = []DependencyType{Compound}
 var DeterminerRels = []DependencyType{Det, DetMod}
 var MultiWord = []DependencyType{MWE, MWPrep, Compound, Parataxis}
 var QuantifingMods = []DependencyType{QuantMod, NumMod}
+
+// FunctionalRels are the relations that attach function words to content words, as defined in CoNLL 2018
+var FunctionalRels = []DependencyType{Aux, AuxPass, Cop, Mark, Det, Predet, DetMod, Case, Coordination, Preconj}
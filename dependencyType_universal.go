@@ -105,3 +105,6 @@ var Compounds = []DependencyType{Compound, Compound_Part}
 var DeterminerRels = []DependencyType{Det, Det_PreDet}
 var MultiWord = []DependencyType{MWE, Compound, Compound_Part, Parataxis}
 var QuantifingMods = []DependencyType{NumMod}
+
+// FunctionalRels are the relations that attach function words to content words, as defined in CoNLL 2018
+var FunctionalRels = []DependencyType{Aux, AuxPass, Cop, Mark, Det, Det_PreDet, Case, Coordination, CC_PreConj}
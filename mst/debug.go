@@ -0,0 +1,11 @@
+// +build debug
+
+package mst
+
+import "log"
+
+const BUILD_DEBUG = "MST PARSER: Debug Build"
+
+func logf(format string, others ...interface{}) {
+	log.Printf(format, others...)
+}
@@ -0,0 +1,415 @@
+package mst
+
+import "math"
+
+// Decoder is the algorithm used to find the highest scoring tree
+type Decoder byte
+
+const (
+	// Eisner finds the highest scoring projective tree
+	Eisner Decoder = iota
+	// ChuLiuEdmonds finds the highest scoring tree, which may be non-projective
+	ChuLiuEdmonds
+
+	MAXDECODER
+)
+
+func (d Decoder) String() string {
+	switch d {
+	case Eisner:
+		return "Eisner"
+	case ChuLiuEdmonds:
+		return "ChuLiuEdmonds"
+	}
+	return "UnknownDecoder"
+}
+
+// siblingScores returns the score of h → d, where s is the previous child of h on the same side as d, or -1 if there isn't one
+type siblingScores func(h, s, d int) float64
+
+const (
+	left = iota
+	right
+)
+
+var negInf = math.Inf(-1)
+
+// chart is a square matrix of scores, with an extra dimension for the direction of the item
+type chart struct {
+	n      int
+	scores []float64
+	bp     []int
+}
+
+func newChart(n int) *chart {
+	return &chart{
+		n:      n,
+		scores: make([]float64, n*n*2),
+		bp:     make([]int, n*n*2),
+	}
+}
+
+func (c *chart) at(s, t, dir int) int { return (s*c.n+t)*2 + dir }
+
+// eisner finds the highest scoring projective tree with the first-order arc scores (scores[h][d]), using Eisner's algorithm.
+// If sib is not nil, siblings are scored as well with McDonald and Pereira's second-order extension.
+//
+// The root is the first word, and only has one child. The returned heads have -1 as the head of the root.
+func eisner(scores [][]float64, sib siblingScores) []int {
+	n := len(scores)
+	heads := make([]int, n)
+	heads[0] = -1
+	if n == 1 {
+		return heads
+	}
+
+	// complete, incomplete and sibling items. Only the words are covered - the root is attached at the end
+	C := newChart(n)
+	I := newChart(n)
+	S := newChart(n)
+
+	for k := 1; k < n; k++ {
+		for s := 1; s+k < n; s++ {
+			t := s + k
+
+			// sibling items: s and t are adjacent children of the same head
+			best, bq := negInf, -1
+			for q := s; q < t; q++ {
+				if v := C.scores[C.at(s, q, right)] + C.scores[C.at(q+1, t, left)]; v > best {
+					best, bq = v, q
+				}
+			}
+			S.scores[S.at(s, t, left)], S.bp[S.at(s, t, left)] = best, bq
+
+			if sib == nil {
+				I.scores[I.at(s, t, left)] = best + scores[t][s]
+				I.scores[I.at(s, t, right)] = best + scores[s][t]
+				I.bp[I.at(s, t, left)], I.bp[I.at(s, t, right)] = bq, bq
+			} else {
+				// s → t. The previous sibling r of t is between s and t
+				best, br := C.scores[C.at(s+1, t, left)]+sib(s, -1, t), -1
+				for r := s + 1; r < t; r++ {
+					if v := I.scores[I.at(s, r, right)] + S.scores[S.at(r, t, left)] + sib(s, r, t); v > best {
+						best, br = v, r
+					}
+				}
+				I.scores[I.at(s, t, right)], I.bp[I.at(s, t, right)] = best+scores[s][t], br
+
+				// t → s.
+				best, br = C.scores[C.at(s, t-1, right)]+sib(t, -1, s), -1
+				for r := s + 1; r < t; r++ {
+					if v := S.scores[S.at(s, r, left)] + I.scores[I.at(r, t, left)] + sib(t, r, s); v > best {
+						best, br = v, r
+					}
+				}
+				I.scores[I.at(s, t, left)], I.bp[I.at(s, t, left)] = best+scores[t][s], br
+			}
+
+			// complete items headed by t
+			best, bq = negInf, -1
+			for q := s; q < t; q++ {
+				if v := C.scores[C.at(s, q, left)] + I.scores[I.at(q, t, left)]; v > best {
+					best, bq = v, q
+				}
+			}
+			C.scores[C.at(s, t, left)], C.bp[C.at(s, t, left)] = best, bq
+
+			// complete items headed by s
+			best, bq = negInf, -1
+			for q := s + 1; q <= t; q++ {
+				if v := I.scores[I.at(s, q, right)] + C.scores[C.at(q, t, right)]; v > best {
+					best, bq = v, q
+				}
+			}
+			C.scores[C.at(s, t, right)], C.bp[C.at(s, t, right)] = best, bq
+		}
+	}
+
+	// attach the root to exactly one word
+	best, br := negInf, -1
+	for r := 1; r < n; r++ {
+		v := C.scores[C.at(1, r, left)] + C.scores[C.at(r, n-1, right)] + scores[0][r]
+		if sib != nil {
+			v += sib(0, -1, r)
+		}
+		if v > best {
+			best, br = v, r
+		}
+	}
+	heads[br] = 0
+
+	var complete, incomplete, sibling func(s, t, dir int)
+	complete = func(s, t, dir int) {
+		if s == t {
+			return
+		}
+		q := C.bp[C.at(s, t, dir)]
+		if dir == left {
+			complete(s, q, left)
+			incomplete(q, t, left)
+			return
+		}
+		incomplete(s, q, right)
+		complete(q, t, right)
+	}
+	sibling = func(s, t, _ int) {
+		q := S.bp[S.at(s, t, left)]
+		complete(s, q, right)
+		complete(q+1, t, left)
+	}
+	incomplete = func(s, t, dir int) {
+		if dir == left {
+			heads[s] = t
+		} else {
+			heads[t] = s
+		}
+
+		r := I.bp[I.at(s, t, dir)]
+		switch {
+		case sib == nil:
+			sibling(s, t, dir)
+		case dir == right && r < 0:
+			complete(s+1, t, left)
+		case dir == right:
+			incomplete(s, r, right)
+			sibling(r, t, dir)
+		case r < 0:
+			complete(s, t-1, right)
+		default:
+			sibling(s, r, dir)
+			incomplete(r, t, left)
+		}
+	}
+	complete(1, br, left)
+	complete(br, n-1, right)
+	return heads
+}
+
+// chuLiuEdmonds finds the highest scoring tree with the arc scores (scores[h][d]) using the Chu-Liu/Edmonds algorithm.
+// The tree may be non-projective. The root is the first word, and only has one child. The returned heads have -1 as the head of the root.
+func chuLiuEdmonds(scores [][]float64) []int {
+	n := len(scores)
+	if n == 1 {
+		return []int{-1}
+	}
+
+	// Every tree has at least one arc from the root. If every arc from the root costs more than any tree could score,
+	// the best tree has exactly one, and it's the best of the trees with one.
+	min, max := math.Inf(1), math.Inf(-1)
+	for h := range scores {
+		for d := 1; d < n; d++ {
+			if h == d || math.IsInf(scores[h][d], 0) {
+				continue
+			}
+			min = math.Min(min, scores[h][d])
+			max = math.Max(max, scores[h][d])
+		}
+	}
+	penalty := 1 + (max-min)*float64(n)
+
+	s := make([][]float64, n)
+	for h := range s {
+		s[h] = make([]float64, n)
+		for d := range s[h] {
+			switch {
+			case d == 0 || h == d:
+				s[h][d] = negInf
+			case h == 0:
+				s[h][d] = scores[h][d] - penalty
+			default:
+				s[h][d] = scores[h][d]
+			}
+		}
+	}
+	return cle(s)
+}
+
+// cle is the recursive part of the Chu-Liu/Edmonds algorithm
+func cle(scores [][]float64) []int {
+	n := len(scores)
+	heads := make([]int, n)
+	heads[0] = -1
+	for d := 1; d < n; d++ {
+		best := negInf
+		heads[d] = 0
+		for h := 0; h < n; h++ {
+			if h != d && scores[h][d] > best {
+				best = scores[h][d]
+				heads[d] = h
+			}
+		}
+	}
+
+	cycle := findCycle(heads)
+	if cycle == nil {
+		return heads
+	}
+
+	// contract the cycle into one node, which is the last node of the contracted graph
+	inCycle := make([]bool, n)
+	for _, v := range cycle {
+		inCycle[v] = true
+	}
+	var outside []int
+	ids := make([]int, n)
+	for v := 0; v < n; v++ {
+		if !inCycle[v] {
+			ids[v] = len(outside)
+			outside = append(outside, v)
+		}
+	}
+	m := len(outside) + 1
+	c := m - 1
+
+	cs := make([][]float64, m)
+	for i := range cs {
+		cs[i] = make([]float64, m)
+		for j := range cs[i] {
+			cs[i][j] = negInf
+		}
+	}
+	enter := make([]int, m) // the word in the cycle that an arc into the cycle attaches to
+	leave := make([]int, m) // the word in the cycle that an arc out of the cycle comes from
+	for _, u := range outside {
+		for _, w := range outside {
+			cs[ids[u]][ids[w]] = scores[u][w]
+		}
+		for _, v := range cycle {
+			// entering the cycle at v breaks the arc into v
+			if s := scores[u][v] - scores[heads[v]][v]; s > cs[ids[u]][c] {
+				cs[ids[u]][c] = s
+				enter[ids[u]] = v
+			}
+			if s := scores[v][u]; s > cs[c][ids[u]] {
+				cs[c][ids[u]] = s
+				leave[ids[u]] = v
+			}
+		}
+	}
+
+	sub := cle(cs)
+	for i, w := range outside {
+		switch h := sub[i]; {
+		case h < 0:
+		case h == c:
+			heads[w] = leave[i]
+		default:
+			heads[w] = outside[h]
+		}
+	}
+	h := sub[c]
+	heads[enter[h]] = outside[h]
+	return heads
+}
+
+// findCycle returns the words in a cycle, if there is one
+func findCycle(heads []int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(heads))
+	for start := range heads {
+		if state[start] != unvisited {
+			continue
+		}
+
+		v := start
+		var path []int
+		for v >= 0 && state[v] == unvisited {
+			state[v] = visiting
+			path = append(path, v)
+			v = heads[v]
+		}
+		if v >= 0 && state[v] == visiting {
+			// v is the start of the cycle
+			for i, p := range path {
+				if p == v {
+					return path[i:]
+				}
+			}
+		}
+		for _, p := range path {
+			state[p] = visited
+		}
+	}
+	return nil
+}
+
+// rearrange improves a tree found with the second-order Eisner algorithm by reattaching words, as long as the score improves.
+// This is the approximate non-projective second-order decoder described by McDonald and Pereira (2006).
+func rearrange(heads []int, scores [][]float64, sib siblingScores) []int {
+	n := len(heads)
+	best := treeScore(heads, scores, sib)
+	for {
+		bestH, bestD := -1, -1
+		for d := 1; d < n; d++ {
+			orig := heads[d]
+			for h := 0; h < n; h++ {
+				if h == d || h == orig || isAncestor(d, h, heads) || (h == 0) != (orig == 0) {
+					continue // the tree must stay a tree, with only one child of the root
+				}
+
+				heads[d] = h
+				if s := treeScore(heads, scores, sib); s > best+1e-9 {
+					best, bestH, bestD = s, h, d
+				}
+				heads[d] = orig
+			}
+		}
+		if bestD < 0 {
+			return heads
+		}
+		heads[bestD] = bestH
+	}
+}
+
+// isAncestor checks if a is an ancestor of (or is) w
+func isAncestor(a, w int, heads []int) bool {
+	for i := 0; w >= 0 && i < len(heads); i++ {
+		if w == a {
+			return true
+		}
+		w = heads[w]
+	}
+	return false
+}
+
+// treeScore is the score of a tree: the sum of the scores of its arcs, and of its siblings
+func treeScore(heads []int, scores [][]float64, sib siblingScores) float64 {
+	var retVal float64
+	for d := 1; d < len(heads); d++ {
+		retVal += scores[heads[d]][d]
+	}
+	if sib == nil {
+		return retVal
+	}
+
+	siblings(heads, func(h, s, d int) float64 {
+		retVal += sib(h, s, d)
+		return 0
+	})
+	return retVal
+}
+
+// siblings calls fn with every pair of adjacent siblings of a tree
+func siblings(heads []int, fn siblingScores) {
+	for h := range heads {
+		prev := -1
+		for d := h - 1; d > 0; d-- {
+			if heads[d] == h {
+				fn(h, prev, d)
+				prev = d
+			}
+		}
+
+		prev = -1
+		for d := h + 1; d < len(heads); d++ {
+			if heads[d] == h {
+				fn(h, prev, d)
+				prev = d
+			}
+		}
+	}
+}
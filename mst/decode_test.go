@@ -0,0 +1,133 @@
+package mst
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomScores(r *rand.Rand, n int) [][]float64 {
+	scores := make([][]float64, n)
+	for h := range scores {
+		scores[h] = make([]float64, n)
+		for d := range scores[h] {
+			scores[h][d] = r.NormFloat64()
+		}
+	}
+	return scores
+}
+
+func randomSiblingScores(r *rand.Rand, n int) siblingScores {
+	sibs := make([]float64, n*(n+1)*n)
+	for i := range sibs {
+		sibs[i] = r.NormFloat64()
+	}
+	return func(h, s, d int) float64 { return sibs[(h*(n+1)+s+1)*n+d] }
+}
+
+// isTree checks that the heads form a tree rooted at the first word, with only one child of the root
+func isTree(heads []int) bool {
+	var rootChildren int
+	for d := 1; d < len(heads); d++ {
+		if heads[d] < 0 || heads[d] == d {
+			return false
+		}
+		if heads[d] == 0 {
+			rootChildren++
+		}
+	}
+	return heads[0] == -1 && rootChildren == 1 && findCycle(heads) == nil
+}
+
+func isProjective(heads []int) bool {
+	for d := 1; d < len(heads); d++ {
+		lo, hi := heads[d], d
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for i := lo + 1; i < hi; i++ {
+			if !isAncestor(heads[d], i, heads) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// bruteForce finds the best tree by trying every possible assignment of heads
+func bruteForce(n int, projective bool, score func([]int) float64) ([]int, float64) {
+	heads := make([]int, n)
+	heads[0] = -1
+	var best []int
+	bestScore := math.Inf(-1)
+
+	var assign func(d int)
+	assign = func(d int) {
+		if d == n {
+			if !isTree(heads) || (projective && !isProjective(heads)) {
+				return
+			}
+			if s := score(heads); s > bestScore {
+				bestScore = s
+				best = append(best[:0], heads...)
+			}
+			return
+		}
+		for h := 0; h < n; h++ {
+			if h != d {
+				heads[d] = h
+				assign(d + 1)
+			}
+		}
+	}
+	assign(1)
+	return best, bestScore
+}
+
+func TestDecoders(t *testing.T) {
+	r := rand.New(rand.NewSource(1337))
+	for trial := 0; trial < 50; trial++ {
+		n := 2 + r.Intn(5)
+		scores := randomScores(r, n)
+		sib := randomSiblingScores(r, n)
+		first := func(heads []int) float64 { return treeScore(heads, scores, nil) }
+		second := func(heads []int) float64 { return treeScore(heads, scores, sib) }
+
+		_, want := bruteForce(n, true, first)
+		heads := eisner(scores, nil)
+		if !isTree(heads) || !isProjective(heads) {
+			t.Fatalf("Eisner: %v is not a projective tree", heads)
+		}
+		if got := first(heads); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Eisner: Expected the best projective tree to score %v. Got %v (%v)", want, got, heads)
+		}
+
+		_, want = bruteForce(n, false, first)
+		heads = chuLiuEdmonds(scores)
+		if !isTree(heads) {
+			t.Fatalf("Chu-Liu/Edmonds: %v is not a tree", heads)
+		}
+		if got := first(heads); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Chu-Liu/Edmonds: Expected the best tree to score %v. Got %v (%v)", want, got, heads)
+		}
+
+		_, want = bruteForce(n, true, second)
+		heads = eisner(scores, sib)
+		if !isTree(heads) || !isProjective(heads) {
+			t.Fatalf("Second order Eisner: %v is not a projective tree", heads)
+		}
+		if got := second(heads); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Second order Eisner: Expected the best projective tree to score %v. Got %v (%v)", want, got, heads)
+		}
+
+		// the approximate non-projective second order decoder is never worse than the projective one
+		proj := second(heads)
+		heads = rearrange(heads, scores, sib)
+		if !isTree(heads) {
+			t.Fatalf("Rearrange: %v is not a tree", heads)
+		}
+		if got := second(heads); got < proj-1e-9 {
+			t.Errorf("Rearrange: Expected the score to be at least %v. Got %v", proj, got)
+		}
+	}
+}
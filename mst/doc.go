@@ -0,0 +1,12 @@
+// Package mst is a graph-based dependency parser. It is an alternative to the transition-based parser in package dep.
+//
+// Every possible arc between the words of a sentence is scored, and the highest scoring tree is found with a maximum spanning tree decoder:
+//
+//	Eisner:        projective trees, as described by Eisner in "Three New Probabilistic Models for Dependency Parsing" (1996)
+//	ChuLiuEdmonds: non-projective trees, as described by McDonald et al. in "Non-projective Dependency Parsing using Spanning Tree Algorithms" (2005)
+//
+// The arcs are scored by an averaged perceptron over sparse features (McDonald, Crammer and Pereira 2005), or by a small feed forward neural network.
+// A second-order model also scores adjacent siblings (McDonald and Pereira 2006). The labels of the arcs are predicted by a separate perceptron.
+//
+// The parser produces the same *lingo.Dependency as the dep package, so the output can be evaluated with dep.Evaluate.
+package mst
@@ -0,0 +1,180 @@
+package mst
+
+import "github.com/chewxy/lingo"
+
+// feature is a hashed sparse feature
+type feature uint64
+
+// hasher is an incremental FNV-1a hash, used to build features from their parts
+type hasher uint64
+
+const (
+	fnvOffset hasher = 14695981039346656037
+	fnvPrime  hasher = 1099511628211
+)
+
+func template(id byte) hasher { return (fnvOffset ^ hasher(id)) * fnvPrime }
+
+func (h hasher) str(s string) hasher {
+	for i := 0; i < len(s); i++ {
+		h = (h ^ hasher(s[i])) * fnvPrime
+	}
+	return (h ^ 0xff) * fnvPrime // separator, so that "ab"+"c" and "a"+"bc" hash differently
+}
+
+func (h hasher) int(v int) hasher {
+	for i := uint(0); i < 64; i += 8 {
+		h = (h ^ hasher(byte(v>>i))) * fnvPrime
+	}
+	return h
+}
+
+func (h hasher) feature() feature { return feature(h) }
+
+// word is the information of a word that is used in features
+type word struct {
+	form string
+	tag  int
+}
+
+const (
+	noTag   = -1 // the tag of words before the start and after the end of the sentence
+	noWord  = "-NONE-"
+	maxDist = 10
+)
+
+// words extracts the forms and tags of the sentence. The root is the first word.
+func words(s lingo.AnnotatedSentence) []word {
+	retVal := make([]word, len(s))
+	for i, a := range s {
+		form := a.Lowered
+		if form == "" {
+			form = a.Value
+		}
+		retVal[i] = word{form, int(a.POSTag)}
+	}
+	return retVal
+}
+
+func tagAt(ws []word, i int) int {
+	if i < 0 || i >= len(ws) {
+		return noTag
+	}
+	return ws[i].tag
+}
+
+// direction and distance of an arc. Distances are bucketed.
+func dirDist(h, d int) int {
+	dist := d - h
+	dir := 0
+	if dist < 0 {
+		dist = -dist
+		dir = 1
+	}
+	switch {
+	case dist >= maxDist:
+		dist = maxDist
+	case dist >= 5:
+		dist = 5
+	}
+	return dir*(maxDist+1) + dist
+}
+
+// arcFeatures extracts the first order features of the arc h → d, as described by McDonald, Crammer and Pereira (2005).
+// Every feature is extracted twice - on its own, and conjoined with the direction and distance of the arc.
+func arcFeatures(ws []word, h, d int, buf []feature) []feature {
+	hw, ht := ws[h].form, ws[h].tag
+	dw, dt := ws[d].form, ws[d].tag
+	dd := dirDist(h, d)
+
+	emit := func(f hasher) {
+		buf = append(buf, f.feature(), f.int(dd).feature())
+	}
+
+	// unigram features
+	emit(template(1).str(hw).int(ht))
+	emit(template(2).str(hw))
+	emit(template(3).int(ht))
+	emit(template(4).str(dw).int(dt))
+	emit(template(5).str(dw))
+	emit(template(6).int(dt))
+
+	// bigram features
+	emit(template(7).str(hw).int(ht).str(dw).int(dt))
+	emit(template(8).int(ht).str(dw).int(dt))
+	emit(template(9).str(hw).str(dw).int(dt))
+	emit(template(10).str(hw).int(ht).int(dt))
+	emit(template(11).str(hw).int(ht).str(dw))
+	emit(template(12).str(hw).str(dw))
+	emit(template(13).int(ht).int(dt))
+
+	// tags in between
+	lo, hi := h, d
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	seen := make(map[int]struct{})
+	for i := lo + 1; i < hi; i++ {
+		bt := ws[i].tag
+		if _, ok := seen[bt]; ok {
+			continue
+		}
+		seen[bt] = struct{}{}
+		emit(template(14).int(ht).int(bt).int(dt))
+	}
+
+	// surrounding tags
+	emit(template(15).int(ht).int(tagAt(ws, h+1)).int(tagAt(ws, d-1)).int(dt))
+	emit(template(16).int(tagAt(ws, h-1)).int(ht).int(tagAt(ws, d-1)).int(dt))
+	emit(template(17).int(ht).int(tagAt(ws, h+1)).int(dt).int(tagAt(ws, d+1)))
+	emit(template(18).int(tagAt(ws, h-1)).int(ht).int(dt).int(tagAt(ws, d+1)))
+	return buf
+}
+
+// siblingFeatures extracts the second order features of h → d, where s is the previous child of h on the same side as d
+// (the one between h and d that is closest to d). If d is the child closest to h, s is -1.
+func siblingFeatures(ws []word, h, s, d int, buf []feature) []feature {
+	ht := ws[h].tag
+	dw, dt := ws[d].form, ws[d].tag
+	sw, st := noWord, noTag
+	if s >= 0 {
+		sw, st = ws[s].form, ws[s].tag
+	}
+	dir := 0
+	if d < h {
+		dir = 1
+	}
+
+	emit := func(f hasher) {
+		buf = append(buf, f.feature(), f.int(dir).feature())
+	}
+	emit(template(30).int(ht).int(st).int(dt))
+	emit(template(31).int(st).int(dt))
+	emit(template(32).str(sw).str(dw))
+	emit(template(33).str(sw).int(dt))
+	emit(template(34).int(st).str(dw))
+	return buf
+}
+
+// labelFeatures extracts the features used to label the arc h → d
+func labelFeatures(ws []word, h, d int, buf []feature) []feature {
+	hw, ht := ws[h].form, ws[h].tag
+	dw, dt := ws[d].form, ws[d].tag
+	dd := dirDist(h, d)
+
+	buf = append(buf,
+		template(50).feature(), // bias
+		template(51).str(hw).feature(),
+		template(52).int(ht).feature(),
+		template(53).str(dw).feature(),
+		template(54).int(dt).feature(),
+		template(55).int(ht).int(dt).feature(),
+		template(56).str(hw).int(dt).feature(),
+		template(57).int(ht).str(dw).feature(),
+		template(58).int(ht).int(dt).int(dd).feature(),
+		template(59).int(tagAt(ws, d-1)).int(dt).feature(),
+		template(60).int(dt).int(tagAt(ws, d+1)).feature(),
+		template(61).str(dw).int(dd).feature(),
+	)
+	return buf
+}
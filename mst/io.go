@@ -0,0 +1,122 @@
+package mst
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	perceptronKind byte = iota
+	neuralKind
+)
+
+// Save saves the model
+func (m *Model) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	return m.SaveWriter(f)
+}
+
+// SaveWriter saves the model to the writer, and closes it
+func (m *Model) SaveWriter(f io.WriteCloser) error {
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	defer w.Flush()
+
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(m.decoder); err != nil {
+		return err
+	}
+
+	switch s := m.scorer.(type) {
+	case *perceptronScorer:
+		if err := encoder.Encode(perceptronKind); err != nil {
+			return err
+		}
+		if err := encoder.Encode(s.SecondOrder); err != nil {
+			return err
+		}
+		if err := encoder.Encode(s.weights); err != nil {
+			return err
+		}
+	case *neuralScorer:
+		if err := encoder.Encode(neuralKind); err != nil {
+			return err
+		}
+		if err := encoder.Encode(s); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("Unable to save scorer of %T", s)
+	}
+
+	return encoder.Encode(m.labeler.weights)
+}
+
+// Load loads a model from a file
+func Load(filename string) (*Model, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return LoadReader(f)
+}
+
+// LoadReader loads a model from the reader, and closes it
+func LoadReader(rd io.ReadCloser) (*Model, error) {
+	defer rd.Close()
+
+	r := bufio.NewReader(rd)
+	decoder := gob.NewDecoder(r)
+
+	m := &Model{labeler: newLabeler()}
+	if err := decoder.Decode(&m.decoder); err != nil {
+		return nil, err
+	}
+
+	var kind byte
+	if err := decoder.Decode(&kind); err != nil {
+		return nil, err
+	}
+	switch kind {
+	case perceptronKind:
+		s := newPerceptronScorer(false)
+		if err := decoder.Decode(&s.SecondOrder); err != nil {
+			return nil, err
+		}
+		if err := decoder.Decode(&s.weights); err != nil {
+			return nil, err
+		}
+		m.scorer = s
+	case neuralKind:
+		s := new(neuralScorer)
+		if err := decoder.Decode(s); err != nil {
+			return nil, err
+		}
+		m.scorer = s
+	default:
+		return nil, errors.Errorf("Unknown scorer %d", kind)
+	}
+
+	if err := decoder.Decode(&m.labeler.weights); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Load loads a model into the parser
+func (p *Parser) Load(filename string) error {
+	m, err := Load(filename)
+	if err != nil {
+		return err
+	}
+	p.Model = m
+	return nil
+}
@@ -0,0 +1,232 @@
+package mst
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/chewxy/lingo"
+)
+
+// NeuralConfig configures the neural arc scorer
+type NeuralConfig struct {
+	EmbeddingSize int     // size of the word, POSTag and distance embeddings
+	HiddenSize    int     // size of the hidden layer
+	LearnRate     float64 // learning rate of the stochastic gradient descent
+	Seed          int64   // seed used to initialize the weights
+}
+
+// DefaultNeuralConfig is the default configuration of the neural arc scorer
+var DefaultNeuralConfig = NeuralConfig{
+	EmbeddingSize: 32,
+	HiddenSize:    64,
+	LearnRate:     0.01,
+	Seed:          1337,
+}
+
+// neuralScorer scores arcs with a feed forward network. The embeddings of the head and the dependent, and the direction and distance of the arc
+// are fed into a tanh hidden layer, which is reduced to a score:
+//
+//	score(h, d) = v · tanh(Wh [e(hw); e(ht)] + Wd [e(dw); e(dt)] + Wdist e(dist) + b)
+//
+// It is trained with the structured hinge loss: the scores of the gold arcs are increased, and the scores of the wrongly predicted arcs are decreased.
+// The neural scorer only scores arcs - it's a first order model.
+type neuralScorer struct {
+	NeuralConfig
+	Vocab map[string]int // word → row in Words. 0 is the unknown word
+
+	Words []float64 // Shape: (len(Vocab)+1, EmbeddingSize)
+	Tags  []float64 // Shape: (MAXTAG, EmbeddingSize)
+	Dists []float64 // Shape: (number of direction and distance buckets, EmbeddingSize)
+
+	Wh    []float64 // Shape: (HiddenSize, 2 * EmbeddingSize)
+	Wd    []float64 // Shape: (HiddenSize, 2 * EmbeddingSize)
+	Wdist []float64 // Shape: (HiddenSize, EmbeddingSize)
+	B     []float64 // Shape: (HiddenSize)
+	V     []float64 // Shape: (HiddenSize)
+}
+
+const dists = 2 * (maxDist + 1)
+
+func newNeuralScorer(conf NeuralConfig) *neuralScorer {
+	return &neuralScorer{NeuralConfig: conf}
+}
+
+// init creates the vocabulary and initializes the weights
+func (n *neuralScorer) init(sentences [][]word) {
+	n.Vocab = make(map[string]int)
+	for _, ws := range sentences {
+		for _, w := range ws {
+			if _, ok := n.Vocab[w.form]; !ok {
+				n.Vocab[w.form] = len(n.Vocab) + 1
+			}
+		}
+	}
+
+	r := rand.New(rand.NewSource(n.Seed))
+	e, h := n.EmbeddingSize, n.HiddenSize
+	glorot := func(size, fanIn, fanOut int) []float64 {
+		retVal := make([]float64, size)
+		limit := math.Sqrt(6 / float64(fanIn+fanOut))
+		for i := range retVal {
+			retVal[i] = (r.Float64()*2 - 1) * limit
+		}
+		return retVal
+	}
+	n.Words = glorot((len(n.Vocab)+1)*e, 1, e)
+	n.Tags = glorot(int(lingo.MAXTAG)*e, 1, e)
+	n.Dists = glorot(dists*e, 1, e)
+	n.Wh = glorot(h*2*e, 2*e, h)
+	n.Wd = glorot(h*2*e, 2*e, h)
+	n.Wdist = glorot(h*e, e, h)
+	n.B = make([]float64, h)
+	n.V = glorot(h, h, 1)
+}
+
+func (n *neuralScorer) wordID(w word) int { return n.Vocab[w.form] }
+
+// input is the concatenated word and POSTag embedding of a word
+func (n *neuralScorer) input(w word, buf []float64) []float64 {
+	e := n.EmbeddingSize
+	id := n.wordID(w)
+	buf = append(buf[:0], n.Words[id*e:(id+1)*e]...)
+	return append(buf, n.Tags[w.tag*e:(w.tag+1)*e]...)
+}
+
+// matVec adds m·x to dst
+func matVec(dst, m, x []float64) {
+	cols := len(x)
+	for i := range dst {
+		row := m[i*cols : (i+1)*cols]
+		var sum float64
+		for j, v := range x {
+			sum += row[j] * v
+		}
+		dst[i] += sum
+	}
+}
+
+// parts precomputes the contribution of every word as a head and as a dependent, and of every distance, to the hidden layer
+func (n *neuralScorer) parts(ws []word) (heads, deps, ds [][]float64) {
+	var x []float64
+	heads = make([][]float64, len(ws))
+	deps = make([][]float64, len(ws))
+	for i, w := range ws {
+		x = n.input(w, x)
+		heads[i] = make([]float64, n.HiddenSize)
+		deps[i] = make([]float64, n.HiddenSize)
+		matVec(heads[i], n.Wh, x)
+		matVec(deps[i], n.Wd, x)
+	}
+
+	ds = make([][]float64, dists)
+	e := n.EmbeddingSize
+	for i := range ds {
+		ds[i] = make([]float64, n.HiddenSize)
+		matVec(ds[i], n.Wdist, n.Dists[i*e:(i+1)*e])
+	}
+	return
+}
+
+// hidden computes the activated hidden layer of the arc h → d
+func (n *neuralScorer) hidden(heads, deps, ds [][]float64, h, d int, dst []float64) {
+	hp, dp, dist := heads[h], deps[d], ds[dirDist(h, d)]
+	for i := range dst {
+		dst[i] = math.Tanh(hp[i] + dp[i] + dist[i] + n.B[i])
+	}
+}
+
+func dot(a, b []float64) float64 {
+	var retVal float64
+	for i, v := range a {
+		retVal += v * b[i]
+	}
+	return retVal
+}
+
+func (n *neuralScorer) scores(ws []word) ([][]float64, siblingScores) {
+	heads, deps, ds := n.parts(ws)
+	act := make([]float64, n.HiddenSize)
+	retVal := make([][]float64, len(ws))
+	for h := range retVal {
+		retVal[h] = make([]float64, len(ws))
+		for d := range retVal[h] {
+			if d == 0 || h == d {
+				retVal[h][d] = negInf
+				continue
+			}
+			n.hidden(heads, deps, ds, h, d, act)
+			retVal[h][d] = dot(n.V, act)
+		}
+	}
+	return retVal, nil
+}
+
+func (n *neuralScorer) update(ws []word, gold, predicted []int) {
+	for d := 1; d < len(gold); d++ {
+		if gold[d] == predicted[d] {
+			continue
+		}
+		n.step(ws, gold[d], d, n.LearnRate)
+		n.step(ws, predicted[d], d, -n.LearnRate)
+	}
+}
+
+// step does one step of gradient ascent on the score of h → d, scaled by lr
+func (n *neuralScorer) step(ws []word, h, d int, lr float64) {
+	e, hs := n.EmbeddingSize, n.HiddenSize
+	xh := n.input(ws[h], nil)
+	xd := n.input(ws[d], nil)
+	dd := dirDist(h, d)
+	xdist := n.Dists[dd*e : (dd+1)*e]
+
+	// forwards
+	pre := make([]float64, hs)
+	copy(pre, n.B)
+	matVec(pre, n.Wh, xh)
+	matVec(pre, n.Wd, xd)
+	matVec(pre, n.Wdist, xdist)
+
+	// backwards
+	dpre := make([]float64, hs)
+	for i, p := range pre {
+		act := math.Tanh(p)
+		dpre[i] = lr * n.V[i] * (1 - act*act)
+		n.V[i] += lr * act
+		n.B[i] += dpre[i]
+	}
+
+	dxh := make([]float64, 2*e)
+	dxd := make([]float64, 2*e)
+	dxdist := make([]float64, e)
+	outer := func(w, dx, x []float64) {
+		cols := len(x)
+		for i, g := range dpre {
+			row := w[i*cols : (i+1)*cols]
+			for j, v := range x {
+				dx[j] += row[j] * g
+				row[j] += g * v
+			}
+		}
+	}
+	outer(n.Wh, dxh, xh)
+	outer(n.Wd, dxd, xd)
+	outer(n.Wdist, dxdist, xdist)
+
+	// update the embeddings
+	axpy := func(dst, src []float64) {
+		for i, v := range src {
+			dst[i] += v
+		}
+	}
+	for _, u := range []struct {
+		w  word
+		dx []float64
+	}{{ws[h], dxh}, {ws[d], dxd}} {
+		id := n.wordID(u.w)
+		axpy(n.Words[id*e:(id+1)*e], u.dx[:e])
+		axpy(n.Tags[u.w.tag*e:(u.w.tag+1)*e], u.dx[e:])
+	}
+	axpy(xdist, dxdist)
+}
+
+func (n *neuralScorer) finish() {}
@@ -0,0 +1,207 @@
+package mst
+
+import (
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+)
+
+// arcScorer scores the arcs of a sentence, and learns from its mistakes
+type arcScorer interface {
+	// scores returns the scores of every arc (scores[h][d] is the score of h → d). Second order scorers also return sibling scores.
+	scores(ws []word) ([][]float64, siblingScores)
+
+	// update updates the scorer given the gold tree and the predicted tree of a sentence
+	update(ws []word, gold, predicted []int)
+
+	// finish is called when training is done
+	finish()
+}
+
+// Model is what the Parser parses with. To train a model, use a Parser.
+type Model struct {
+	scorer  arcScorer
+	labeler *labeler
+	decoder Decoder
+}
+
+// Decoder returns the decoder the model uses
+func (m *Model) Decoder() Decoder { return m.decoder }
+
+// SecondOrder returns true if the model scores siblings
+func (m *Model) SecondOrder() bool {
+	p, ok := m.scorer.(*perceptronScorer)
+	return ok && p.SecondOrder
+}
+
+// Parser is a graph-based dependency parser.
+type Parser struct {
+	*Model
+
+	secondOrder bool
+	neural      *NeuralConfig
+}
+
+// ConsOpt is a construction option for a Parser
+type ConsOpt func(*Parser)
+
+// WithModel creates a *Parser with the specified model
+func WithModel(m *Model) ConsOpt {
+	fn := func(p *Parser) {
+		p.Model = m
+	}
+	return fn
+}
+
+// WithDecoder sets the decoder of the model. By default, the Eisner decoder is used.
+func WithDecoder(d Decoder) ConsOpt {
+	fn := func(p *Parser) {
+		p.Model.decoder = d
+	}
+	return fn
+}
+
+// WithSecondOrder makes a new model score adjacent siblings as well as arcs. Only the perceptron scorer supports second order models.
+func WithSecondOrder() ConsOpt {
+	fn := func(p *Parser) {
+		p.secondOrder = true
+	}
+	return fn
+}
+
+// WithNeuralScorer makes a new model score arcs with a neural network instead of a perceptron.
+func WithNeuralScorer(conf NeuralConfig) ConsOpt {
+	fn := func(p *Parser) {
+		p.neural = &conf
+	}
+	return fn
+}
+
+// New creates a new Parser. Unless a model is passed in with WithModel, the Parser has to be trained before it can parse.
+func New(opts ...ConsOpt) *Parser {
+	p := &Parser{
+		Model: &Model{labeler: newLabeler()},
+	}
+	fresh := p.Model
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.Model == fresh {
+		if p.neural != nil {
+			p.scorer = newNeuralScorer(*p.neural)
+		} else {
+			p.scorer = newPerceptronScorer(p.secondOrder)
+		}
+	}
+	return p
+}
+
+// Train trains the parser on the gold trees of the sentences.
+func (p *Parser) Train(sentences []lingo.AnnotatedSentence, epochs int) error {
+	if len(sentences) == 0 {
+		return errors.New("No sentences to train on")
+	}
+	if p.decoder >= MAXDECODER {
+		return errors.Errorf("Unknown decoder %v", p.decoder)
+	}
+	if p.secondOrder && p.neural != nil {
+		return errors.New("The neural scorer only scores arcs. It cannot be used for a second order model")
+	}
+
+	wss := make([][]word, len(sentences))
+	golds := make([][]int, len(sentences))
+	for i, s := range sentences {
+		wss[i] = words(s)
+		golds[i] = make([]int, len(s))
+		golds[i][0] = -1
+		for j, a := range s[1:] {
+			if golds[i][j+1] = a.HeadID(); golds[i][j+1] < 0 {
+				return errors.Errorf("Word %d of sentence %d (%q) has no head", j+1, i, s.ValueString())
+			}
+		}
+	}
+
+	if n, ok := p.scorer.(*neuralScorer); ok && n.Vocab == nil {
+		n.init(wss)
+	}
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		var correct, total int
+		for i, ws := range wss {
+			gold := golds[i]
+
+			// the scores are augmented with the cost of each arc (Taskar et al. 2005), so that the gold tree has to win by a margin
+			scores, sib := p.scorer.scores(ws)
+			for d := 1; d < len(gold); d++ {
+				for h := range scores {
+					if h != gold[d] {
+						scores[h][d]++
+					}
+				}
+			}
+			predicted := p.decodeScores(scores, sib)
+			for d := 1; d < len(gold); d++ {
+				if predicted[d] == gold[d] {
+					correct++
+				}
+				total++
+			}
+			p.scorer.update(ws, gold, predicted)
+
+			var buf []feature
+			for d := 1; d < len(gold); d++ {
+				buf = labelFeatures(ws, gold[d], d, buf[:0])
+				guess := p.labeler.label(buf, gold[d] == 0)
+				p.labeler.update(buf, guess, sentences[i][d].DependencyType)
+			}
+		}
+		logf("Epoch %d: %d/%d heads correct", epoch, correct, total)
+	}
+
+	p.scorer.finish()
+	p.labeler.average()
+	return nil
+}
+
+// decode finds the highest scoring tree
+func (m *Model) decode(ws []word) []int {
+	return m.decodeScores(m.scorer.scores(ws))
+}
+
+func (m *Model) decodeScores(scores [][]float64, sib siblingScores) []int {
+	switch {
+	case m.decoder == ChuLiuEdmonds && sib != nil:
+		return rearrange(eisner(scores, sib), scores, sib)
+	case m.decoder == ChuLiuEdmonds:
+		return chuLiuEdmonds(scores)
+	default:
+		return eisner(scores, sib)
+	}
+}
+
+// Parse parses the sentence. The first annotation of the sentence has to be the root.
+func (m *Model) Parse(s lingo.AnnotatedSentence) (*lingo.Dependency, error) {
+	if len(s) == 0 || s[0] != lingo.RootAnnotation() {
+		return nil, errors.Errorf("Expected %q to start with the root", s.ValueString())
+	}
+
+	// the sentence may already have heads (for example, when a gold sentence is parsed). They would be overwritten, so the sentence is copied
+	for _, a := range s {
+		if a.Head != nil {
+			s = s.Clone()
+			break
+		}
+	}
+
+	ws := words(s)
+	heads := m.decode(ws)
+
+	dep := lingo.NewDependency(lingo.FromAnnotatedSentence(s), lingo.AllocTree())
+	dep.SetID()
+	var buf []feature
+	for d := 1; d < len(heads); d++ {
+		buf = labelFeatures(ws, heads[d], d, buf[:0])
+		dep.AddArc(heads[d], d, m.labeler.label(buf, heads[d] == 0))
+	}
+	return dep, nil
+}
@@ -0,0 +1,114 @@
+package mst
+
+import (
+	"os"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/dep"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseAll(t *testing.T, p *Parser, ss []lingo.AnnotatedSentence) (predicted, gold []*lingo.Dependency) {
+	for _, s := range ss {
+		d, err := p.Parse(s)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if !isTree(d.Heads()) && !isTree(append([]int{-1}, d.Heads()...)) {
+			t.Errorf("Expected a tree. Got %v", d.Heads())
+		}
+		predicted = append(predicted, d)
+		gold = append(gold, s.Dependency())
+	}
+	return
+}
+
+func TestParser(t *testing.T) {
+	ss := allSentences()
+	for _, tc := range []struct {
+		name   string
+		opts   []ConsOpt
+		epochs int
+	}{
+		{"Eisner", nil, 10},
+		{"ChuLiuEdmonds", []ConsOpt{WithDecoder(ChuLiuEdmonds)}, 10},
+		{"Second order Eisner", []ConsOpt{WithSecondOrder()}, 10},
+		{"Second order ChuLiuEdmonds", []ConsOpt{WithSecondOrder(), WithDecoder(ChuLiuEdmonds)}, 10},
+		{"Neural", []ConsOpt{WithNeuralScorer(DefaultNeuralConfig)}, 60},
+	} {
+		p := New(tc.opts...)
+		if err := p.Train(ss, tc.epochs); err != nil {
+			t.Fatalf("%v: %+v", tc.name, err)
+		}
+
+		perf, err := dep.Evaluate(parseAll(t, p, ss))
+		if err != nil {
+			t.Fatalf("%v: %+v", tc.name, err)
+		}
+		t.Logf("%v: %v", tc.name, perf)
+		if perf.UAS < 0.9 {
+			t.Errorf("%v: Expected to learn the training set. UAS: %v", tc.name, perf.UAS)
+		}
+		if perf.Root != 1 {
+			t.Errorf("%v: Expected the roots to be learnt. Got %v", tc.name, perf.Root)
+		}
+
+		// round trip
+		if err := p.Save("mst.dat"); err != nil {
+			t.Fatalf("%v: %+v", tc.name, err)
+		}
+		m, err := Load("mst.dat")
+		os.Remove("mst.dat")
+		if err != nil {
+			t.Fatalf("%v: %+v", tc.name, err)
+		}
+		assert.Equal(t, p.Decoder(), m.Decoder(), tc.name)
+		assert.Equal(t, p.SecondOrder(), m.SecondOrder(), tc.name)
+
+		loaded := New(WithModel(m))
+		for _, s := range ss {
+			want, _ := p.Parse(s)
+			got, err := loaded.Parse(s)
+			if err != nil {
+				t.Fatalf("%v: %+v", tc.name, err)
+			}
+			assert.Equal(t, want.Heads(), got.Heads(), tc.name)
+			assert.Equal(t, want.Labels(), got.Labels(), tc.name)
+		}
+	}
+}
+
+func TestParser_NonProjective(t *testing.T) {
+	ss := sentences(nonprojective)
+	for _, decoder := range []Decoder{Eisner, ChuLiuEdmonds} {
+		p := New(WithDecoder(decoder))
+		if err := p.Train(ss, 10); err != nil {
+			t.Fatal(err)
+		}
+		d, err := p.Parse(ss[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch decoder {
+		case Eisner:
+			assert.True(t, d.IsProjective(), "Eisner only produces projective trees")
+		case ChuLiuEdmonds:
+			assert.Equal(t, ss[0].Dependency().Heads(), d.Heads(), "Expected Chu-Liu/Edmonds to learn the non-projective tree")
+		}
+	}
+}
+
+func TestParser_Train(t *testing.T) {
+	if err := New().Train(nil, 1); err == nil {
+		t.Error("Expected an error when there's nothing to train on")
+	}
+	if err := New(WithSecondOrder(), WithNeuralScorer(DefaultNeuralConfig)).Train(allSentences(), 1); err == nil {
+		t.Error("Expected an error with a second order neural scorer")
+	}
+
+	if _, err := New().Parse(lingo.AnnotatedSentence{}); err == nil {
+		t.Error("Expected an error when parsing a sentence without the root")
+	}
+}
@@ -0,0 +1,224 @@
+package mst
+
+import "github.com/chewxy/lingo"
+
+// averaged is an averaged perceptron over sparse features. The averaging is done lazily, as in package pos.
+type averaged struct {
+	weights map[feature]float64
+	totals  map[feature]float64
+	steps   map[feature]float64
+
+	instancesSeen float64
+}
+
+func newAveraged() *averaged {
+	return &averaged{
+		weights: make(map[feature]float64),
+		totals:  make(map[feature]float64),
+		steps:   make(map[feature]float64),
+	}
+}
+
+func (p *averaged) score(fs []feature) float64 {
+	var retVal float64
+	for _, f := range fs {
+		retVal += p.weights[f]
+	}
+	return retVal
+}
+
+func (p *averaged) update(f feature, value float64) {
+	w := p.weights[f]
+	p.totals[f] += (p.instancesSeen - p.steps[f]) * w
+	p.steps[f] = p.instancesSeen
+	p.weights[f] = w + value
+}
+
+func (p *averaged) average() {
+	if p.instancesSeen == 0 {
+		return
+	}
+	for f, w := range p.weights {
+		total := p.totals[f] + (p.instancesSeen-p.steps[f])*w
+		p.weights[f] = total / p.instancesSeen
+	}
+	p.totals = make(map[feature]float64)
+	p.steps = make(map[feature]float64)
+}
+
+// perceptronScorer scores arcs (and optionally siblings) with an averaged perceptron, as described by McDonald, Crammer and Pereira (2005).
+type perceptronScorer struct {
+	*averaged
+	SecondOrder bool
+}
+
+func newPerceptronScorer(secondOrder bool) *perceptronScorer {
+	return &perceptronScorer{
+		averaged:    newAveraged(),
+		SecondOrder: secondOrder,
+	}
+}
+
+func (p *perceptronScorer) scores(ws []word) ([][]float64, siblingScores) {
+	n := len(ws)
+	retVal := make([][]float64, n)
+	var buf []feature
+	for h := range retVal {
+		retVal[h] = make([]float64, n)
+		for d := range retVal[h] {
+			if d == 0 || h == d {
+				retVal[h][d] = negInf
+				continue
+			}
+			buf = arcFeatures(ws, h, d, buf[:0])
+			retVal[h][d] = p.score(buf)
+		}
+	}
+	if !p.SecondOrder {
+		return retVal, nil
+	}
+
+	// the sibling scores are computed ahead of time, as the decoder asks for most of them anyway. sibs[h][s+1][d]
+	sibs := make([]float64, n*(n+1)*n)
+	for h := 0; h < n; h++ {
+		for s := -1; s < n; s++ {
+			for d := 1; d < n; d++ {
+				if d == h || s == h || s == d {
+					continue
+				}
+				buf = siblingFeatures(ws, h, s, d, buf[:0])
+				sibs[(h*(n+1)+s+1)*n+d] = p.score(buf)
+			}
+		}
+	}
+	sib := func(h, s, d int) float64 { return sibs[(h*(n+1)+s+1)*n+d] }
+	return retVal, sib
+}
+
+func (p *perceptronScorer) update(ws []word, gold, predicted []int) {
+	p.instancesSeen++
+
+	// the features of the gold tree are rewarded, and the features of the predicted tree are penalized.
+	// Features that are in both cancel out.
+	diff := make(map[feature]float64)
+	p.treeFeatures(ws, gold, 1, diff)
+	p.treeFeatures(ws, predicted, -1, diff)
+	for f, v := range diff {
+		if v != 0 {
+			p.averaged.update(f, v)
+		}
+	}
+}
+
+func (p *perceptronScorer) treeFeatures(ws []word, heads []int, value float64, acc map[feature]float64) {
+	var buf []feature
+	for d := 1; d < len(heads); d++ {
+		buf = arcFeatures(ws, heads[d], d, buf[:0])
+		for _, f := range buf {
+			acc[f] += value
+		}
+	}
+	if !p.SecondOrder {
+		return
+	}
+
+	sib := func(h, s, d int) float64 {
+		buf = siblingFeatures(ws, h, s, d, buf[:0])
+		for _, f := range buf {
+			acc[f] += value
+		}
+		return 0
+	}
+	siblings(heads, sib)
+}
+
+func (p *perceptronScorer) finish() { p.average() }
+
+// labeler labels the arcs of a tree with a multiclass averaged perceptron
+type labeler struct {
+	weights map[feature]*[lingo.MAXDEPTYPE]float64
+	totals  map[flTuple]float64
+	steps   map[flTuple]float64
+
+	instancesSeen float64
+}
+
+// feature-label tuple
+type flTuple struct {
+	feature
+	lingo.DependencyType
+}
+
+func newLabeler() *labeler {
+	return &labeler{
+		weights: make(map[feature]*[lingo.MAXDEPTYPE]float64),
+		totals:  make(map[flTuple]float64),
+		steps:   make(map[flTuple]float64),
+	}
+}
+
+// label predicts the label of the arc. Only arcs from the root are labelled Root.
+func (l *labeler) label(fs []feature, fromRoot bool) lingo.DependencyType {
+	if fromRoot {
+		return lingo.Root
+	}
+
+	var scores [lingo.MAXDEPTYPE]float64
+	for _, f := range fs {
+		if weights, ok := l.weights[f]; ok {
+			for i, w := range weights {
+				scores[i] += w
+			}
+		}
+	}
+
+	best := lingo.Dep
+	for i, s := range scores {
+		dt := lingo.DependencyType(i)
+		if dt == lingo.Root || dt == lingo.NoDepType {
+			continue
+		}
+		if s > scores[best] {
+			best = dt
+		}
+	}
+	return best
+}
+
+func (l *labeler) update(fs []feature, guess, truth lingo.DependencyType) {
+	l.instancesSeen++
+	if guess == truth {
+		return
+	}
+	for _, f := range fs {
+		l.updateWeight(f, truth, 1)
+		l.updateWeight(f, guess, -1)
+	}
+}
+
+func (l *labeler) updateWeight(f feature, dt lingo.DependencyType, value float64) {
+	weights, ok := l.weights[f]
+	if !ok {
+		weights = new([lingo.MAXDEPTYPE]float64)
+		l.weights[f] = weights
+	}
+	tuple := flTuple{f, dt}
+	l.totals[tuple] += (l.instancesSeen - l.steps[tuple]) * weights[dt]
+	l.steps[tuple] = l.instancesSeen
+	weights[dt] += value
+}
+
+func (l *labeler) average() {
+	if l.instancesSeen == 0 {
+		return
+	}
+	for f, weights := range l.weights {
+		for i, w := range weights {
+			tuple := flTuple{f, lingo.DependencyType(i)}
+			total := l.totals[tuple] + (l.instancesSeen-l.steps[tuple])*w
+			weights[i] = total / l.instancesSeen
+		}
+	}
+	l.totals = make(map[flTuple]float64)
+	l.steps = make(map[flTuple]float64)
+}
@@ -0,0 +1,7 @@
+// +build !debug
+
+package mst
+
+const BUILD_DEBUG = "MST PARSER: Release Build"
+
+func logf(format string, others ...interface{}) {}
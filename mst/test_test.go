@@ -0,0 +1,101 @@
+package mst
+
+import (
+	"strings"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+	"github.com/pkg/errors"
+)
+
+type dummyFix struct{}
+
+func (dummyFix) Lemmatize(s string, pt lingo.POSTag) ([]string, error) {
+	return nil, errors.New("lemmatizer unavailable")
+}
+
+func (dummyFix) Stem(s string) (string, error) { return s, nil }
+
+func (dummyFix) Clusters() (map[string]lingo.Cluster, error) {
+	return nil, errors.New("clusters unavailable")
+}
+
+const nnps = `1	Guerrillas	guerrilla	NOUN	NNS	Number=Plur	2	nsubj	_	_
+2	threatened	threaten	VERB	VBD	Mood=Ind|Tense=Past|VerbForm=Fin	0	root	_	_
+3	to	to	PART	TO	_	4	mark	_	_
+4	assassinate	assassinate	VERB	VB	VerbForm=Inf	2	xcomp	_	_
+5	Prime	Prime	PROPN	NNP	Number=Sing	6	compound	_	_
+6	Minister	Minister	PROPN	NNP	Number=Sing	8	compound	_	_
+7	Iyad	Iyad	PROPN	NNP	Number=Sing	8	compound	_	_
+8	Allawi	Allawi	PROPN	NNP	Number=Sing	4	dobj	_	_
+9	and	and	CONJ	CC	_	8	cc	_	_
+10	Minister	Minister	PROPN	NNP	Number=Sing	14	compound	_	_
+11	of	of	ADP	IN	_	12	case	_	_
+12	Defense	Defense	PROPN	NNP	Number=Sing	10	nmod	_	_
+13	Hazem	Hazem	PROPN	NNP	Number=Sing	14	compound	_	_
+14	Shaalan	Shaalan	PROPN	NNP	Number=Sing	8	conj	_	_
+15	in	in	ADP	IN	_	16	case	_	_
+16	retaliation	retaliation	NOUN	NN	Number=Sing	4	nmod	_	_
+17	for	for	ADP	IN	_	19	case	_	_
+18	the	the	DET	DT	Definite=Def|PronType=Art	19	det	_	_
+19	attack	attack	NOUN	NN	Number=Sing	16	nmod	_	_
+20	.	.	PUNCT	.	_	2	punct	_	_
+
+`
+const simple = `1	Yet	yet	CONJ	CC	_	5	cc	_	_
+2	we	we	PRON	PRP	Case=Nom|Number=Plur|Person=1|PronType=Prs	5	nsubj	_	_
+3	did	do	AUX	VBD	Mood=Ind|Tense=Past|VerbForm=Fin	5	aux	_	_
+4	n't	not	PART	RB	_	5	neg	_	_
+5	charge	charge	VERB	VB	VerbForm=Inf	0	root	_	_
+6	them	they	PRON	PRP	Case=Acc|Number=Plur|Person=3|PronType=Prs	5	dobj	_	_
+7	for	for	ADP	IN	_	9	case	_	_
+8	the	the	DET	DT	Definite=Def|PronType=Art	9	det	_	_
+9	evacuation	evacuation	NOUN	NN	Number=Sing	5	nmod	_	_
+10	.	.	PUNCT	.	_	5	punct	_	_
+
+`
+
+const med = `1	President	President	PROPN	NNP	Number=Sing	2	compound	_	_
+2	Bush	Bush	PROPN	NNP	Number=Sing	5	nsubj	_	_
+3	on	on	ADP	IN	_	4	case	_	_
+4	Tuesday	Tuesday	PROPN	NNP	Number=Sing	5	nmod	_	_
+5	nominated	nominate	VERB	VBD	Mood=Ind|Tense=Past|VerbForm=Fin	0	root	_	_
+6	two	two	NUM	CD	NumType=Card	7	nummod	_	_
+7	individuals	individual	NOUN	NNS	Number=Plur	5	dobj	_	_
+8	to	to	PART	TO	_	9	mark	_	_
+9	replace	replace	VERB	VB	VerbForm=Inf	5	advcl	_	_
+10	retiring	retire	VERB	VBG	VerbForm=Ger	11	amod	_	_
+11	jurists	jurist	NOUN	NNS	Number=Plur	9	dobj	_	_
+12	on	on	ADP	IN	_	14	case	_	_
+13	federal	federal	ADJ	JJ	Degree=Pos	14	amod	_	_
+14	courts	court	NOUN	NNS	Number=Plur	11	nmod	_	_
+15	in	in	ADP	IN	_	18	case	_	_
+16	the	the	DET	DT	Definite=Def|PronType=Art	18	det	_	_
+17	Washington	Washington	PROPN	NNP	Number=Sing	18	compound	_	_
+18	area	area	NOUN	NN	Number=Sing	14	nmod	_	_
+19	.	.	PUNCT	.	_	5	punct	_	_
+
+`
+const nonprojective = `1	A	a	DET	DT	_	2	det	_	_
+2	hearing	hearing	NOUN	NN	_	4	nsubj	_	_
+3	is	be	AUX	VBZ	_	4	aux	_	_
+4	scheduled	schedule	VERB	VBN	_	0	root	_	_
+5	on	on	ADP	IN	_	7	case	_	_
+6	the	the	DET	DT	_	7	det	_	_
+7	issue	issue	NOUN	NN	_	2	nmod	_	_
+8	today	today	NOUN	NN	_	4	nmod	_	_
+9	.	.	PUNCT	.	_	4	punct	_	_
+
+`
+
+func sentences(conllus ...string) []lingo.AnnotatedSentence {
+	var retVal []lingo.AnnotatedSentence
+	for _, c := range conllus {
+		for _, st := range treebank.ReadConllu(strings.NewReader(c)) {
+			retVal = append(retVal, st.AnnotatedSentence(dummyFix{}))
+		}
+	}
+	return retVal
+}
+
+func allSentences() []lingo.AnnotatedSentence { return sentences(nnps, simple, med, nonprojective) }
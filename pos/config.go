@@ -0,0 +1,53 @@
+package pos
+
+import "fmt"
+
+// Config holds the thresholds the Tagger uses to build its shortcuts, its tag dictionary and its unknown word guesser.
+type Config struct {
+	// Words seen at least ShortcutFreq times in training, whose most common tag makes up at least
+	// ShortcutAmbiguity of the times seen, are always tagged with that tag.
+	ShortcutFreq      int     // 30
+	ShortcutAmbiguity float64 // 0.98
+
+	// Words seen at least DictFreq times in training are only tagged with tags they've been seen with.
+	// Tags making up less than DictMinRatio of the times a word was seen are not considered.
+	// If DictFreq is 0, the tag dictionary is not used.
+	DictFreq     int     // 20
+	DictMinRatio float64 // 0
+
+	// Unknown words are only tagged with the most likely tags proposed by the guesser, up to a cumulative probability of GuesserMass.
+	// The guesser learns the tags of the suffixes (up to GuesserMaxSuffix characters long) and shapes of words seen at most GuesserRareFreq times.
+	// If GuesserMass is 0, the guesser is not used.
+	GuesserMaxSuffix int     // 5
+	GuesserRareFreq  int     // 10
+	GuesserMass      float64 // 0.999
+}
+
+func (c Config) String() string {
+	s := `Shortcut Frequency       : %d
+Shortcut Ambiguity       : %f
+Tag Dictionary Frequency : %d
+Tag Dictionary Min Ratio : %f
+Guesser Max Suffix       : %d
+Guesser Rare Frequency   : %d
+Guesser Mass             : %f
+`
+	return fmt.Sprintf(s, c.ShortcutFreq, c.ShortcutAmbiguity, c.DictFreq, c.DictMinRatio, c.GuesserMaxSuffix, c.GuesserRareFreq, c.GuesserMass)
+}
+
+// DefaultConfig is the default config that is used when a new Tagger is created.
+var DefaultConfig Config
+
+func init() {
+	DefaultConfig = Config{
+		ShortcutFreq:      30,
+		ShortcutAmbiguity: 0.98,
+
+		DictFreq:     20,
+		DictMinRatio: 0,
+
+		GuesserMaxSuffix: 5,
+		GuesserRareFreq:  10,
+		GuesserMass:      0.999,
+	}
+}
@@ -4,5 +4,5 @@ import "fmt"
 
 type componentUnavailable string
 
-func (c componentUnavailable) Error() string     { return fmt.Sprintf("%v unavailable", c) }
+func (c componentUnavailable) Error() string     { return fmt.Sprintf("%v unavailable", string(c)) }
 func (c componentUnavailable) Component() string { return string(c) }
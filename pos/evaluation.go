@@ -0,0 +1,193 @@
+package pos
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+)
+
+// Vocabulary knows which words were seen in training. *Model is a Vocabulary.
+type Vocabulary interface {
+	Known(word string) bool
+}
+
+// TagMetrics holds the performance of the tagger on one tag
+type TagMetrics struct {
+	Gold      int     `json:"gold"`      // number of words tagged with the tag in the gold standard
+	Predicted int     `json:"predicted"` // number of words the tagger tagged with the tag
+	Correct   int     `json:"correct"`   // number of words the tagger tagged with the tag that are tagged with the tag in the gold standard
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// Evaluation is the performance of a tagger against a gold standard.
+// It can be printed as text, or marshalled into JSON with encoding/json.
+type Evaluation struct {
+	Tokens   int     `json:"tokens"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+
+	// known and unknown words are only counted if a Vocabulary was passed into Evaluate
+	KnownTokens     int     `json:"knownTokens"`
+	KnownCorrect    int     `json:"knownCorrect"`
+	KnownAccuracy   float64 `json:"knownAccuracy"`
+	UnknownTokens   int     `json:"unknownTokens"`
+	UnknownCorrect  int     `json:"unknownCorrect"`
+	UnknownAccuracy float64 `json:"unknownAccuracy"`
+
+	Sentences        int     `json:"sentences"`
+	ExactMatches     int     `json:"exactMatches"` // sentences where every word is tagged correctly
+	ExactMatch       float64 `json:"exactMatch"`
+	DifferentLengths int     `json:"differentLengths"` // sentences where the predicted sentence and the gold sentence have different lengths
+
+	Tags      map[string]TagMetrics     `json:"tags"`      // keyed by tag
+	Confusion map[string]map[string]int `json:"confusion"` // gold tag → predicted tag → count
+}
+
+// Evaluate compares the predicted sentences with the gold standard sentences. The vocabulary is used to tell known words from unknown words, and may be nil.
+//
+// Sentences are compared word by word. If a predicted sentence and its gold sentence have different lengths, the words can't be aligned,
+// so all the words of the sentence are counted as wrong, and none of them are counted as predicted.
+// It returns an error if the number of predicted sentences and the number of gold sentences aren't the same.
+func Evaluate(predicted, gold []lingo.AnnotatedSentence, vocab Vocabulary) (Evaluation, error) {
+	if len(predicted) != len(gold) {
+		return Evaluation{}, errors.Errorf("%d predicted sentences; %d gold sentences. Unable to compare", len(predicted), len(gold))
+	}
+
+	e := Evaluation{
+		Sentences: len(gold),
+		Tags:      make(map[string]TagMetrics),
+		Confusion: make(map[string]map[string]int),
+	}
+
+	for i, g := range gold {
+		p := predicted[i]
+		sameLength := len(p) == len(g)
+		if !sameLength {
+			e.DifferentLengths++
+		}
+
+		exact := sameLength
+		for j, a := range g {
+			if a == lingo.RootAnnotation() {
+				continue
+			}
+
+			truth := a.POSTag
+			correct := sameLength && p[j].POSTag == truth
+			e.Tokens++
+			if correct {
+				e.Correct++
+			} else {
+				exact = false
+			}
+
+			if vocab != nil {
+				if vocab.Known(a.Value) {
+					e.KnownTokens++
+					if correct {
+						e.KnownCorrect++
+					}
+				} else {
+					e.UnknownTokens++
+					if correct {
+						e.UnknownCorrect++
+					}
+				}
+			}
+
+			tm := e.Tags[truth.String()]
+			tm.Gold++
+			if correct {
+				tm.Correct++
+			}
+			e.Tags[truth.String()] = tm
+
+			if sameLength {
+				guess := p[j].POSTag
+				tm = e.Tags[guess.String()]
+				tm.Predicted++
+				e.Tags[guess.String()] = tm
+
+				if _, ok := e.Confusion[truth.String()]; !ok {
+					e.Confusion[truth.String()] = make(map[string]int)
+				}
+				e.Confusion[truth.String()][guess.String()]++
+			}
+		}
+
+		if exact {
+			e.ExactMatches++
+		}
+	}
+
+	e.Accuracy = ratio(e.Correct, e.Tokens)
+	e.KnownAccuracy = ratio(e.KnownCorrect, e.KnownTokens)
+	e.UnknownAccuracy = ratio(e.UnknownCorrect, e.UnknownTokens)
+	e.ExactMatch = ratio(e.ExactMatches, e.Sentences)
+
+	for t, tm := range e.Tags {
+		tm.Precision = ratio(tm.Correct, tm.Predicted)
+		tm.Recall = ratio(tm.Correct, tm.Gold)
+		if tm.Precision+tm.Recall > 0 {
+			tm.F1 = 2 * tm.Precision * tm.Recall / (tm.Precision + tm.Recall)
+		}
+		e.Tags[t] = tm
+	}
+
+	return e, nil
+}
+
+func (e Evaluation) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Accuracy         : %d/%d = %.5f\n", e.Correct, e.Tokens, e.Accuracy)
+	fmt.Fprintf(&buf, "Known Accuracy   : %d/%d = %.5f\n", e.KnownCorrect, e.KnownTokens, e.KnownAccuracy)
+	fmt.Fprintf(&buf, "Unknown Accuracy : %d/%d = %.5f\n", e.UnknownCorrect, e.UnknownTokens, e.UnknownAccuracy)
+	fmt.Fprintf(&buf, "Exact Match      : %d/%d = %.5f\n", e.ExactMatches, e.Sentences, e.ExactMatch)
+	fmt.Fprintf(&buf, "Different Lengths: %d/%d\n\n", e.DifferentLengths, e.Sentences)
+
+	tags := make([]string, 0, len(e.Tags))
+	for t := range e.Tags {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Tag\tGold\tPredicted\tCorrect\tPrecision\tRecall\tF1\t")
+	for _, t := range tags {
+		tm := e.Tags[t]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.5f\t%.5f\t%.5f\t\n", t, tm.Gold, tm.Predicted, tm.Correct, tm.Precision, tm.Recall, tm.F1)
+	}
+	w.Flush()
+
+	// confusion matrix: rows are the gold tags, columns are the predicted tags
+	buf.WriteString("\nConfusion Matrix (rows: gold, columns: predicted)\n")
+	w = tabwriter.NewWriter(&buf, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "\t")
+	for _, t := range tags {
+		fmt.Fprintf(w, "%s\t", t)
+	}
+	fmt.Fprintln(w)
+	for _, g := range tags {
+		fmt.Fprintf(w, "%s\t", g)
+		for _, p := range tags {
+			fmt.Fprintf(w, "%d\t", e.Confusion[g][p])
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
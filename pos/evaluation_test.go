@@ -0,0 +1,86 @@
+package pos
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type vocab map[string]bool
+
+func (v vocab) Known(word string) bool { return v[word] }
+
+func TestEvaluate(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := lingo.POSTag(1), lingo.POSTag(2)
+	sentence := func(words []string, tags ...lingo.POSTag) lingo.AnnotatedSentence {
+		s := lingo.AnnotatedSentence{lingo.RootAnnotation()}
+		for i, w := range words {
+			s = append(s, lingo.AnnotationFromLexTag(lingo.Lexeme{Value: w, LexemeType: lingo.Word}, tags[i], nil))
+		}
+		return s
+	}
+
+	gold := []lingo.AnnotatedSentence{
+		sentence([]string{"known", "unknown"}, a, b),
+		sentence([]string{"known", "known"}, a, a),
+	}
+	predicted := []lingo.AnnotatedSentence{
+		sentence([]string{"known", "unknown"}, a, b),
+		sentence([]string{"known", "known"}, a, b),
+	}
+
+	e, err := Evaluate(predicted, gold, vocab{"known": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(4, e.Tokens)
+	assert.Equal(3, e.Correct)
+	assert.Equal(0.75, e.Accuracy)
+	assert.Equal(3, e.KnownTokens)
+	assert.Equal(2, e.KnownCorrect)
+	assert.Equal(1.0, e.UnknownAccuracy)
+	assert.Equal(1, e.ExactMatches)
+	assert.Equal(0.5, e.ExactMatch)
+
+	assert.Equal(TagMetrics{Gold: 3, Predicted: 2, Correct: 2, Precision: 1, Recall: 2.0 / 3.0, F1: 0.8}, e.Tags[a.String()])
+	assert.Equal(TagMetrics{Gold: 1, Predicted: 2, Correct: 1, Precision: 0.5, Recall: 1, F1: 2.0 / 3.0}, e.Tags[b.String()])
+	assert.Equal(1, e.Confusion[a.String()][b.String()])
+	assert.Equal(2, e.Confusion[a.String()][a.String()])
+
+	// all the words of a sentence of a different length count as wrong
+	predicted[1] = predicted[1][:2]
+	e, err = Evaluate(predicted, gold, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(4, e.Tokens)
+	assert.Equal(2, e.Correct)
+	assert.Equal(1, e.DifferentLengths)
+	assert.Equal(TagMetrics{Gold: 3, Predicted: 1, Correct: 1, Precision: 1, Recall: 1.0 / 3.0, F1: 0.5}, e.Tags[a.String()])
+	assert.Equal(1, e.Confusion[a.String()][a.String()])
+	assert.Equal(0, e.KnownTokens+e.UnknownTokens)
+
+	if !strings.Contains(e.String(), "Confusion Matrix") {
+		t.Errorf("Expected the confusion matrix to be printed. Got\n%v", e)
+	}
+
+	bs, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e2 Evaluation
+	if err := json.Unmarshal(bs, &e2); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(e, e2)
+
+	if _, err := Evaluate(predicted[:1], gold, nil); err == nil {
+		t.Error("Expected an error when the number of sentences differ")
+	}
+}
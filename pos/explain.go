@@ -0,0 +1,175 @@
+package pos
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/chewxy/lingo"
+)
+
+// explainTop is the number of contributions that is printed by Explanation.String()
+const explainTop = 10
+
+// Contribution is the weight a feature contributes to the score of a tag
+type Contribution struct {
+	Feature string
+	Weight  float64
+}
+
+// TagExplanation is the score of a tag, and the features that make up the score,
+// sorted by how much they contributed (the largest absolute weight first)
+type TagExplanation struct {
+	Tag           lingo.POSTag
+	Score         float64
+	Contributions []Contribution
+}
+
+// Explanation explains why a word was tagged the way it was.
+type Explanation struct {
+	Word string
+
+	Chosen   TagExplanation // the tag the word was tagged with
+	RunnerUp TagExplanation // the tag with the next highest score
+
+	// Shortcut is true if the chosen tag came from the shortcuts and not the perceptron.
+	// The scores of the perceptron are still explained.
+	Shortcut bool
+
+	// Candidates are the tags that were considered, as limited by the tag dictionary or the unknown word guesser.
+	// If all tags were considered, Candidates is nil.
+	Candidates []lingo.POSTag
+}
+
+func (e Explanation) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%q: %v (score %.3f)", e.Word, e.Chosen.Tag, e.Chosen.Score)
+	if e.Shortcut {
+		buf.WriteString(" [shortcut]")
+	}
+	fmt.Fprintf(&buf, ", runner-up: %v (score %.3f)\n", e.RunnerUp.Tag, e.RunnerUp.Score)
+	if e.Candidates != nil {
+		fmt.Fprintf(&buf, "\tCandidates: %v\n", e.Candidates)
+	}
+
+	for _, te := range []TagExplanation{e.Chosen, e.RunnerUp} {
+		fmt.Fprintf(&buf, "\t%v:\n", te.Tag)
+		for i, c := range te.Contributions {
+			if i >= explainTop {
+				fmt.Fprintf(&buf, "\t\t... %d more\n", len(te.Contributions)-explainTop)
+				break
+			}
+			fmt.Fprintf(&buf, "\t\t%+.3f\t%s\n", c.Weight, c.Feature)
+		}
+	}
+	return buf.String()
+}
+
+// Explain explains the tag of the ith word of the sentence.
+//
+// The tags of the words before i are features, so the sentence should be one that has been tagged by the model.
+// The tags of the ith word onwards are ignored - they were not known when the ith word was tagged.
+// The sentence is not modified.
+func (m *Model) Explain(s lingo.AnnotatedSentence, i int) Explanation {
+	// the tags from i onwards were not known when the ith word was tagged
+	s2 := make(lingo.AnnotatedSentence, len(s))
+	copy(s2, s)
+	for j := i; j < len(s2); j++ {
+		if s2[j] == lingo.NullAnnotation() || s2[j] == lingo.RootAnnotation() || s2[j] == lingo.StartAnnotation() {
+			continue
+		}
+		s2[j] = s2[j].Clone()
+		s2[j].POSTag = lingo.X
+	}
+
+	a := s2[i]
+	sf, tf := m.features(s2, i, new(featureBuffer))
+	allowed := m.candidates(a)
+
+	contribs := make([][]Contribution, lingo.MAXTAG)
+	var scores [lingo.MAXTAG]float64
+	for _, f := range sf {
+		if weights, ok := m.weightsSF[f]; ok {
+			name := m.featureName(f.featureType, f.value)
+			for label, weight := range weights {
+				if weight == 0 {
+					continue
+				}
+				scores[label] += weight
+				contribs[label] = append(contribs[label], Contribution{name, weight})
+			}
+		}
+	}
+	for _, f := range tf {
+		if weights, ok := m.weightsTF[f]; ok {
+			name := m.featureName(f.featureType, f.value1, f.value2)
+			for label, weight := range weights {
+				if weight == 0 {
+					continue
+				}
+				scores[label] += weight
+				contribs[label] = append(contribs[label], Contribution{name, weight})
+			}
+		}
+	}
+
+	retVal := Explanation{Word: a.Value}
+	tag, ok := m.shortcut(a.Lexeme)
+	if !ok {
+		tag = maxScoreAmong(&scores, allowed)
+	}
+	retVal.Shortcut = ok
+
+	// the runner up is the best of the other candidates. If there are no other candidates, the best of the other tags.
+	var others [lingo.MAXTAG]bool
+	var hasOthers bool
+	for t := range others {
+		others[t] = lingo.POSTag(t) != tag && (allowed == nil || allowed[t])
+		hasOthers = hasOthers || others[t]
+	}
+	if !hasOthers {
+		for t := range others {
+			others[t] = lingo.POSTag(t) != tag
+		}
+	}
+	runnerUp := maxScoreAmong(&scores, &others)
+
+	if allowed != nil {
+		for t, ok := range allowed {
+			if ok {
+				retVal.Candidates = append(retVal.Candidates, lingo.POSTag(t))
+			}
+		}
+	}
+
+	retVal.Chosen = TagExplanation{tag, scores[tag], sortContributions(contribs[tag])}
+	retVal.RunnerUp = TagExplanation{runnerUp, scores[runnerUp], sortContributions(contribs[runnerUp])}
+	return retVal
+}
+
+// featureName returns a human readable name of a feature with the given values
+func (m *Model) featureName(ft featureType, values ...string) string {
+	name := ft.String()
+	if ft >= MAXFEATURETYPE && int(ft-MAXFEATURETYPE) < len(m.templates) {
+		t := m.templates[ft-MAXFEATURETYPE]
+		if len(t) == 0 {
+			// a template without parts is a bias feature, which has no value
+			return "bias"
+		}
+		name = t.String()
+		values = strings.Split(values[0], "\x1f")
+	}
+	if ft == bias {
+		return name
+	}
+	return fmt.Sprintf("%s=%s", name, strings.Join(values, "|"))
+}
+
+func sortContributions(contribs []Contribution) []Contribution {
+	sort.SliceStable(contribs, func(i, j int) bool {
+		return math.Abs(contribs[i].Weight) > math.Abs(contribs[j].Weight)
+	})
+	return contribs
+}
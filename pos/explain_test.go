@@ -0,0 +1,67 @@
+package pos
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+)
+
+func TestModel_Explain(t *testing.T) {
+	sentences := treebank.ReadConllu(strings.NewReader(conllu))
+	p := New(WithCluster(clusters))
+	p.Train(sentences, 20)
+
+	for _, st := range sentences {
+		s := st.AnnotatedSentence(dummyFix{})
+		for _, a := range s[1:] {
+			a.POSTag = lingo.X
+		}
+
+		var tags []lingo.POSTag
+		for i, a := range s {
+			tag, ok := p.shortcut(a.Lexeme)
+			if !ok {
+				sf, tf := p.features(s, i, &p.buf)
+				tag = p.perceptron.predictAmong(sf, tf, p.candidates(a))
+			}
+			p.setTag(a, tag)
+			tags = append(tags, a.POSTag)
+		}
+
+		for i, a := range s {
+			if a == lingo.RootAnnotation() {
+				continue
+			}
+			e := p.Explain(s, i)
+			if e.Chosen.Tag != tags[i] {
+				t.Errorf("Explaining %q: Expected the chosen tag to be %v. Got %v", a.Value, tags[i], e.Chosen.Tag)
+			}
+			if s[i].POSTag != tags[i] {
+				t.Errorf("Explain should not modify the sentence")
+			}
+			if e.RunnerUp.Tag == e.Chosen.Tag {
+				t.Errorf("Explaining %q: the runner-up should be a different tag", a.Value)
+			}
+			if !e.Shortcut && e.Chosen.Score < e.RunnerUp.Score {
+				t.Errorf("Explaining %q: the chosen tag scored %v, lower than the runner-up's %v", a.Value, e.Chosen.Score, e.RunnerUp.Score)
+			}
+
+			var sum float64
+			for j, c := range e.Chosen.Contributions {
+				sum += c.Weight
+				if c.Weight == 0 {
+					t.Errorf("Explaining %q: %v does not contribute to the score", a.Value, c.Feature)
+				}
+				if j > 0 && math.Abs(c.Weight) > math.Abs(e.Chosen.Contributions[j-1].Weight) {
+					t.Errorf("Explaining %q: contributions are not sorted", a.Value)
+				}
+			}
+			if math.Abs(sum-e.Chosen.Score) > 1e-6 {
+				t.Errorf("Explaining %q: the contributions add up to %v. The score is %v", a.Value, sum, e.Chosen.Score)
+			}
+		}
+	}
+}
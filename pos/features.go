@@ -114,6 +114,13 @@ func (fm *featureMap) add(f feature) { (*fm)[f]++ }
 type sfFeatures [prevLemma_prevPOSTag]singleFeature
 type tfFeatures [MAXFEATURETYPE - prevLemma_prevPOSTag]tupleFeature
 
+// featureBuffer holds the features of a word while it is being tagged. It is reused for every word, so tagging doesn't allocate the features.
+type featureBuffer struct {
+	sf        sfFeatures
+	tf        tfFeatures
+	templated []singleFeature // features extracted by the feature templates of the model
+}
+
 func fillFromContext(c contextMap) (sf sfFeatures, tf tfFeatures) {
 	for i := bias; i < prevLemma_prevPOSTag; i++ {
 		sf[i] = singleFeature{i, c[featCtxMap[i]]}
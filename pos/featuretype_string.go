@@ -4,9 +4,9 @@ package pos
 
 import "fmt"
 
-const _featureType_name = "biasithWord_prevLemma_prevPOSTagprev2Lemma_prev2POSTagnextWord_next2Word_ithSuffix3_ithPrefix1_prevPOSTag_prev2POSTag_prevPOSTag_prev2_POSTagprevPOSTag_ithWordprevSuffix3_nextSuffix3_ithShape_ithCluster_nextCluster_next2Cluster_prevCluster_prev2Cluster_ithFlags_nextFlags_next2Flags_prevFlags_prev2Flags_MAXFEATURETYPE"
+const _featureType_name = "biasithWord_nextWord_next2Word_ithSuffix3_ithPrefix1_prevPOSTag_prev2POSTag_prevSuffix3_nextSuffix3_ithShape_ithCluster_nextCluster_next2Cluster_prevCluster_prev2Cluster_ithFlags_nextFlags_next2Flags_prevFlags_prev2Flags_prevLemma_prevPOSTagprevPOSTag_ithWordprevPOSTag_prev2POSTagprev2Lemma_prev2POSTagMAXFEATURETYPE"
 
-var _featureType_index = [...]uint16{0, 4, 12, 32, 54, 63, 73, 84, 95, 106, 118, 141, 159, 171, 183, 192, 203, 215, 228, 240, 253, 262, 272, 283, 293, 304, 318}
+var _featureType_index = [...]uint16{0, 4, 12, 21, 31, 42, 53, 64, 76, 88, 100, 109, 120, 132, 145, 157, 170, 179, 189, 200, 210, 221, 241, 259, 281, 303, 317}
 
 func (i featureType) String() string {
 	if i >= featureType(len(_featureType_index)-1) {
@@ -0,0 +1,209 @@
+package pos
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+)
+
+// guesser guesses the tags of unknown words from their suffixes and shapes.
+// It learns from rare words, as rare words in the training set are the most similar to unknown words.
+//
+// The suffix probabilities are smoothed by successive abstraction as described by Thorsten Brants in
+// "TnT - A Statistical Part-of-Speech Tagger" (2000). The suffix and shape probabilities are then combined.
+type guesser struct {
+	suffixes map[string]*[lingo.MAXTAG]int
+	shapes   map[lingo.Shape]*[lingo.MAXTAG]int
+	prior    [lingo.MAXTAG]int
+
+	maxSuffix int
+}
+
+func newGuesser(maxSuffix int) *guesser {
+	return &guesser{
+		suffixes:  make(map[string]*[lingo.MAXTAG]int),
+		shapes:    make(map[lingo.Shape]*[lingo.MAXTAG]int),
+		maxSuffix: maxSuffix,
+	}
+}
+
+// train adds the words of the sentences which have been seen at most rareFreq times (according to the counts) to the guesser
+func (g *guesser) train(sentences []treebank.SentenceTag, counts map[string]map[lingo.POSTag]int, rareFreq int) {
+	for _, sentenceTag := range sentences {
+		for i, lex := range sentenceTag.Sentence {
+			var n int
+			for _, c := range counts[lex.Value] {
+				n += c
+			}
+			if n > rareFreq {
+				continue
+			}
+			g.add(lex, sentenceTag.Tags[i])
+		}
+	}
+}
+
+func (g *guesser) add(lex lingo.Lexeme, tag lingo.POSTag) {
+	g.prior[tag]++
+
+	shape := lex.Shape()
+	if _, ok := g.shapes[shape]; !ok {
+		g.shapes[shape] = new([lingo.MAXTAG]int)
+	}
+	g.shapes[shape][tag]++
+
+	lowered := []rune(strings.ToLower(lex.Value))
+	for n := 1; n <= g.maxSuffix && n <= len(lowered); n++ {
+		suffix := string(lowered[len(lowered)-n:])
+		if _, ok := g.suffixes[suffix]; !ok {
+			g.suffixes[suffix] = new([lingo.MAXTAG]int)
+		}
+		g.suffixes[suffix][tag]++
+	}
+}
+
+// probs returns the probability of each tag given the annotation
+func (g *guesser) probs(a *lingo.Annotation) (retVal [lingo.MAXTAG]float64, ok bool) {
+	var total int
+	for _, c := range g.prior {
+		total += c
+	}
+	if total == 0 {
+		return retVal, false
+	}
+
+	var prior [lingo.MAXTAG]float64
+	for t, c := range g.prior {
+		prior[t] = float64(c) / float64(total)
+	}
+
+	// θ is the standard deviation of the prior
+	mean := 1 / float64(lingo.MAXTAG)
+	var theta float64
+	for _, p := range prior {
+		theta += (p - mean) * (p - mean)
+	}
+	theta = math.Sqrt(theta / float64(lingo.MAXTAG-1))
+
+	// successive abstraction over the suffixes, from the shortest to the longest
+	retVal = prior
+	loweredRunes := []rune(a.Lowered)
+	for n := 1; n <= g.maxSuffix && n <= len(loweredRunes); n++ {
+		counts, ok := g.suffixes[string(loweredRunes[len(loweredRunes)-n:])]
+		if !ok {
+			break
+		}
+		smooth(&retVal, counts, theta)
+	}
+
+	// combine with the shape
+	if counts, ok := g.shapes[a.Shape]; ok {
+		shape := prior
+		smooth(&shape, counts, theta)
+
+		var sum float64
+		for t := range retVal {
+			if prior[t] > 0 {
+				retVal[t] *= shape[t] / prior[t]
+			}
+			sum += retVal[t]
+		}
+		for t := range retVal {
+			retVal[t] /= sum
+		}
+	}
+	return retVal, true
+}
+
+// guess returns the most likely tags of the annotation, such that their probabilities add up to at least mass.
+func (g *guesser) guess(a *lingo.Annotation, mass float64) *[lingo.MAXTAG]bool {
+	probs, ok := g.probs(a)
+	if !ok {
+		return nil
+	}
+
+	tags := make([]lingo.POSTag, lingo.MAXTAG)
+	for i := range tags {
+		tags[i] = lingo.POSTag(i)
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return probs[tags[i]] > probs[tags[j]] })
+
+	retVal := new([lingo.MAXTAG]bool)
+	var cumulative float64
+	for _, t := range tags {
+		retVal[t] = true
+		cumulative += probs[t]
+		if cumulative >= mass {
+			break
+		}
+	}
+	return retVal
+}
+
+// smooth updates the probabilities p with the counts, weighted by θ
+func smooth(p *[lingo.MAXTAG]float64, counts *[lingo.MAXTAG]int, theta float64) {
+	var total int
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return
+	}
+
+	for t, c := range counts {
+		p[t] = (float64(c)/float64(total) + theta*p[t]) / (1 + theta)
+	}
+}
+
+/* Gob interface */
+
+func (g *guesser) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+
+	if err := encoder.Encode(g.suffixes); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Encode(g.shapes); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Encode(g.prior); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Encode(g.maxSuffix); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *guesser) GobDecode(buf []byte) error {
+	b := bytes.NewBuffer(buf)
+	decoder := gob.NewDecoder(b)
+
+	if err := decoder.Decode(&g.suffixes); err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(&g.shapes); err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(&g.prior); err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(&g.maxSuffix); err != nil {
+		return err
+	}
+
+	return nil
+}
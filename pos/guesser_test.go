@@ -0,0 +1,133 @@
+package pos
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuesser(t *testing.T) {
+	ing, ly := lingo.POSTag(1), lingo.POSTag(2)
+
+	var sentences []treebank.SentenceTag
+	for _, w := range []string{"running", "jumping", "swimming", "eating", "sleeping"} {
+		sentences = append(sentences, treebank.SentenceTag{
+			Sentence: lingo.LexemeSentence{{Value: w, LexemeType: lingo.Word}},
+			Tags:     []lingo.POSTag{ing},
+		})
+	}
+	for _, w := range []string{"quickly", "slowly", "happily", "badly"} {
+		sentences = append(sentences, treebank.SentenceTag{
+			Sentence: lingo.LexemeSentence{{Value: w, LexemeType: lingo.Word}},
+			Tags:     []lingo.POSTag{ly},
+		})
+	}
+
+	g := newGuesser(3)
+	g.train(sentences, countTags(sentences), 10)
+
+	a := lingo.AnnotationFromLexTag(lingo.Lexeme{Value: "Walking", LexemeType: lingo.Word}, lingo.X, nil)
+	probs, ok := g.probs(a)
+	if !ok {
+		t.Fatal("Expected the trained guesser to have probabilities")
+	}
+	if probs[ing] <= probs[ly] {
+		t.Errorf("Expected %q to be more likely tagged %v than %v. Got %v and %v", a.Value, ing, ly, probs[ing], probs[ly])
+	}
+
+	allowed := g.guess(a, 0.5)
+	if !allowed[ing] || allowed[ly] {
+		t.Errorf("Expected only %v to be guessed for %q", ing, a.Value)
+	}
+
+	a = lingo.AnnotationFromLexTag(lingo.Lexeme{Value: "sadly", LexemeType: lingo.Word}, lingo.X, nil)
+	allowed = g.guess(a, 0.5)
+	if !allowed[ly] || allowed[ing] {
+		t.Errorf("Expected only %v to be guessed for %q", ly, a.Value)
+	}
+
+	if newGuesser(3).guess(a, 0.5) != nil {
+		t.Error("Expected an untrained guesser to not guess")
+	}
+}
+
+func TestModel_candidates(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &Model{
+		tagCounts: map[string]map[lingo.POSTag]int{
+			"rare":   {1: 1},
+			"common": {1: 90, 2: 9, 3: 1},
+			"even":   {1: 10, 2: 10, 3: 10, 4: 5},
+		},
+		guesser: newGuesser(3),
+		config:  DefaultConfig,
+	}
+
+	rare := lingo.AnnotationFromLexTag(lingo.Lexeme{Value: "rare", LexemeType: lingo.Word}, lingo.X, nil)
+	common := lingo.AnnotationFromLexTag(lingo.Lexeme{Value: "common", LexemeType: lingo.Word}, lingo.X, nil)
+
+	assert.Nil(m.candidates(rare), "words seen fewer than DictFreq times may be any tag")
+
+	allowed := m.candidates(common)
+	assert.NotNil(allowed)
+	assert.True(allowed[1] && allowed[2] && allowed[3])
+	assert.False(allowed[4])
+
+	m.config.DictMinRatio = 0.05
+	allowed = m.candidates(common)
+	assert.True(allowed[1] && allowed[2])
+	assert.False(allowed[3], "tags below DictMinRatio should not be considered")
+
+	even := lingo.AnnotationFromLexTag(lingo.Lexeme{Value: "even", LexemeType: lingo.Word}, lingo.X, nil)
+	m.config.DictMinRatio = 0.5
+	allowed = m.candidates(even)
+	assert.True(allowed[1] && allowed[2] && allowed[3], "if no tag reaches DictMinRatio, the most frequent tags should be considered")
+	assert.False(allowed[4])
+	assert.False(allowed[0])
+
+	m.config.DictFreq = 0
+	assert.Nil(m.candidates(common), "the tag dictionary should not be used if DictFreq is 0")
+
+	assert.True(m.Known("rare"))
+	assert.False(m.Known("unseen"))
+}
+
+func TestTagger_Config(t *testing.T) {
+	assert := assert.New(t)
+	sentences := treebank.ReadConllu(strings.NewReader(conllu))
+
+	conf := DefaultConfig
+	conf.ShortcutFreq = 1
+	conf.ShortcutAmbiguity = 1
+	conf.GuesserMaxSuffix = 2
+
+	p := New(WithConfig(conf))
+	p.Train(sentences, 5)
+	assert.Equal(conf, p.Config())
+
+	counts := countTags(sentences)
+	for word, tagCounter := range counts {
+		if len(tagCounter) == 1 {
+			_, ok := p.cachedTags[word]
+			assert.True(ok, "unambiguous words should be shortcutted with a ShortcutFreq of 1")
+		}
+	}
+
+	if err := p.Save("config.dat"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove("config.dat")
+
+	m, err := Load("config.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(conf, m.Config())
+	assert.Equal(p.tagCounts, m.tagCounts)
+	assert.Equal(p.guesser, m.guesser)
+}
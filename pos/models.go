@@ -13,6 +13,79 @@ import (
 type Model struct {
 	*perceptron
 	cachedTags map[string]lingo.POSTag
+	templates  []FeatureTemplate // if nil, the default (compiled) features are used
+
+	tagCounts map[string]map[lingo.POSTag]int // the tag dictionary - how many times a word has been seen with a tag in training
+	guesser   *guesser                        // guesses the tags of unknown words
+	config    Config
+}
+
+// Config returns the thresholds the model uses
+func (m *Model) Config() Config { return m.config }
+
+// Known returns true if the word was seen during training
+func (m *Model) Known(word string) bool {
+	_, ok := m.tagCounts[word]
+	return ok
+}
+
+// candidates returns the tags that may be considered for the annotation. If all tags may be considered, nil is returned.
+//
+// Words seen often enough in training are restricted to the tags they have been seen with,
+// and unknown words are restricted to the tags the guesser proposes.
+func (m *Model) candidates(a *lingo.Annotation) *[lingo.MAXTAG]bool {
+	if a == lingo.NullAnnotation() || a == lingo.RootAnnotation() || a == lingo.StartAnnotation() {
+		return nil
+	}
+
+	counts, known := m.tagCounts[a.Value]
+	switch {
+	case known && m.config.DictFreq > 0:
+		var n int
+		for _, c := range counts {
+			n += c
+		}
+		if n < m.config.DictFreq {
+			return nil
+		}
+
+		allowed := new([lingo.MAXTAG]bool)
+		var qualified bool
+		var most int
+		for t, c := range counts {
+			if float64(c)/float64(n) >= m.config.DictMinRatio {
+				allowed[t] = true
+				qualified = true
+			}
+			if c > most {
+				most = c
+			}
+		}
+
+		// if no tag is frequent enough, the most frequent tags are kept
+		if !qualified {
+			for t, c := range counts {
+				allowed[t] = c == most
+			}
+		}
+		return allowed
+	case !known && m.guesser != nil && m.config.GuesserMass > 0:
+		return m.guesser.guess(a, m.config.GuesserMass)
+	}
+	return nil
+}
+
+// FeatureTemplates returns the feature templates the model uses. If the model uses the default features, nil is returned.
+func (m *Model) FeatureTemplates() []FeatureTemplate { return m.templates }
+
+// features extracts the features of the ith word of a sentence into the buffer. The returned features are only valid until the buffer is reused.
+func (m *Model) features(s lingo.AnnotatedSentence, i int, buf *featureBuffer) ([]singleFeature, []tupleFeature) {
+	if m.templates != nil {
+		buf.templated = templateFeatures(m.templates, s, i, buf.templated[:0])
+		return buf.templated, nil
+	}
+	buf.sf, buf.tf = getFeatures(s, i)
+	return buf.sf[:], buf.tf[:]
 }
 
 // Save saves the model
@@ -40,6 +113,22 @@ func (m *Model) SaveWriter(f io.WriteCloser) error {
 		return err
 	}
 
+	if err := encoder.Encode(m.templates); err != nil {
+		return err
+	}
+
+	if err := encoder.Encode(m.tagCounts); err != nil {
+		return err
+	}
+
+	if err := encoder.Encode(m.guesser); err != nil {
+		return err
+	}
+
+	if err := encoder.Encode(m.config); err != nil {
+		return err
+	}
+
 	return nil
 
 }
@@ -60,6 +149,7 @@ func LoadReader(rd io.ReadCloser) (*Model, error) {
 
 	m := &Model{
 		perceptron: newPerceptron(),
+		guesser:    newGuesser(0),
 	}
 	if err := decoder.Decode(m.perceptron); err != nil {
 		return nil, err
@@ -69,6 +159,32 @@ func LoadReader(rd io.ReadCloser) (*Model, error) {
 		return nil, err
 	}
 
+	// models saved by older versions end early. They have no feature templates, tag dictionary or guesser
+	if err := decoder.Decode(&m.templates); err != nil {
+		if err == io.EOF {
+			m.tagCounts = make(map[string]map[lingo.POSTag]int)
+			m.config = DefaultConfig
+			return m, nil
+		}
+		return nil, err
+	}
+
+	if err := checkFeatureTemplates(m.templates); err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(&m.tagCounts); err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(m.guesser); err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(&m.config); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 
 }
@@ -54,7 +54,7 @@ func (p *perceptron) updateWeightsTF(f tupleFeature, tag lingo.POSTag, weight, v
 	p.weightsTF[f][tag] = weight + value
 }
 
-func (p *perceptron) update(guess, truth lingo.POSTag, sf sfFeatures, tf tfFeatures) {
+func (p *perceptron) update(guess, truth lingo.POSTag, sf []singleFeature, tf []tupleFeature) {
 	p.instancesSeen++
 	if truth == guess {
 		return
@@ -87,7 +87,12 @@ func (p *perceptron) update(guess, truth lingo.POSTag, sf sfFeatures, tf tfFeatu
 	}
 }
 
-func (p *perceptron) predict(sf sfFeatures, tf tfFeatures) lingo.POSTag {
+func (p *perceptron) predict(sf []singleFeature, tf []tupleFeature) lingo.POSTag {
+	return p.predictAmong(sf, tf, nil)
+}
+
+// predictAmong predicts the tag, but only considers the allowed tags. If allowed is nil, all tags are considered.
+func (p *perceptron) predictAmong(sf []singleFeature, tf []tupleFeature, allowed *[lingo.MAXTAG]bool) lingo.POSTag {
 	var scores [lingo.MAXTAG]float64
 	for _, f := range sf {
 		if weights, ok := p.weightsSF[f]; ok {
@@ -105,7 +110,7 @@ func (p *perceptron) predict(sf sfFeatures, tf tfFeatures) lingo.POSTag {
 		}
 	}
 
-	return maxScore(&scores)
+	return maxScoreAmong(&scores, allowed)
 }
 
 func (p *perceptron) average() {
@@ -133,3 +138,49 @@ func (p *perceptron) average() {
 		}
 	}
 }
+
+// clone makes a copy of the weights of the perceptron. The averaging state is not copied.
+func (p *perceptron) clone() *perceptron {
+	retVal := newPerceptron()
+	for f, weights := range p.weightsSF {
+		w := *weights
+		retVal.weightsSF[f] = &w
+	}
+	for f, weights := range p.weightsTF {
+		w := *weights
+		retVal.weightsTF[f] = &w
+	}
+	return retVal
+}
+
+// regularize moves the weights towards the weights of the anchor. Features that the anchor does not know about are moved towards 0.
+// The moves are applied as updates, so the averaging is kept correct.
+func (p *perceptron) regularize(anchor *perceptron, strength float64) {
+	for f, weights := range p.weightsSF {
+		orig := anchor.weightsSF[f]
+		for c, weight := range weights {
+			var o float64
+			if orig != nil {
+				o = orig[c]
+			}
+
+			if delta := strength * (o - weight); delta != 0 {
+				p.updateWeightsSF(f, lingo.POSTag(c), weight, delta)
+			}
+		}
+	}
+
+	for f, weights := range p.weightsTF {
+		orig := anchor.weightsTF[f]
+		for c, weight := range weights {
+			var o float64
+			if orig != nil {
+				o = orig[c]
+			}
+
+			if delta := strength * (o - weight); delta != 0 {
+				p.updateWeightsTF(f, lingo.POSTag(c), weight, delta)
+			}
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package pos
 
 import (
+	"math/rand"
+
 	"github.com/chewxy/lingo"
 	"github.com/chewxy/lingo/corpus"
 	"github.com/chewxy/lingo/treebank"
@@ -26,6 +28,13 @@ type Tagger struct {
 	lingo.Stemmer
 	corpus   *corpus.Corpus
 	clusters map[string]lingo.Cluster // this map is safe for concurrent access because it's readonly
+
+	updateReg float64           // how strongly Update() pulls the weights back towards the weights it started with
+	templates []FeatureTemplate // feature templates for a new model
+	overrides *Config           // if not nil, overrides the config of the model
+	rng       *rand.Rand        // if not nil, the training sentences are shuffled with it
+
+	buf featureBuffer
 }
 
 // ConsOpt is a construction option for a Tagger
@@ -74,6 +83,45 @@ func WithModel(m *Model) ConsOpt {
 	return fn
 }
 
+// WithUpdateRegularization creates a *Tagger that regularizes towards the original weights when Update() is called.
+// The strength is a value between 0 and 1. After every epoch of Update(), each weight is moved that fraction of the way back to what it was before Update() was called.
+// This limits how much a model forgets when it is adapted to new data. A strength of 0 (the default) means no regularization.
+func WithUpdateRegularization(strength float64) ConsOpt {
+	fn := func(p *Tagger) {
+		p.updateReg = strength
+	}
+	return fn
+}
+
+// WithFeatureTemplates creates a *Tagger whose model uses the given feature templates instead of the default features.
+// The feature templates are part of the model, so this option has no effect when a model is passed in with WithModel.
+// A model can have at most MAXTEMPLATES templates. If there are more, Train returns an error.
+func WithFeatureTemplates(templates ...FeatureTemplate) ConsOpt {
+	fn := func(p *Tagger) {
+		p.templates = templates
+	}
+	return fn
+}
+
+// WithConfig creates a *Tagger with the given thresholds for its shortcuts, tag dictionary and unknown word guesser.
+// If a model is passed in with WithModel, the config of the model is replaced.
+// The thresholds for building the shortcuts and the guesser only take effect when the Tagger is trained.
+func WithConfig(c Config) ConsOpt {
+	fn := func(p *Tagger) {
+		p.overrides = &c
+	}
+	return fn
+}
+
+// WithSeed creates a *Tagger that shuffles the training sentences after every iteration with random numbers seeded by the given seed.
+// Training with the same sentences and seed gives the same model. By default the sentences are shuffled the same way after every iteration.
+func WithSeed(seed int64) ConsOpt {
+	fn := func(p *Tagger) {
+		p.rng = rand.New(rand.NewSource(seed))
+	}
+	return fn
+}
+
 // New creates a new *Tagger
 func New(opts ...ConsOpt) *Tagger {
 	p := &Tagger{
@@ -87,8 +135,21 @@ func New(opts ...ConsOpt) *Tagger {
 	}
 
 	if p.Model == nil {
-		p.Model = &Model{perceptron: newPerceptron()}
-		p.cachedTags = make(map[string]lingo.POSTag)
+		p.Model = &Model{
+			perceptron: newPerceptron(),
+			cachedTags: make(map[string]lingo.POSTag),
+			templates:  p.templates,
+			tagCounts:  make(map[string]map[lingo.POSTag]int),
+			config:     DefaultConfig,
+		}
+	}
+
+	if p.overrides != nil {
+		p.Model.config = *p.overrides
+	}
+
+	if p.guesser == nil {
+		p.guesser = newGuesser(p.Model.config.GuesserMaxSuffix)
 	}
 
 	return p
@@ -107,6 +168,10 @@ func (p *Tagger) Clone() *Tagger {
 		Lemmatizer: p.Lemmatizer,
 		Stemmer:    p.Stemmer,
 		clusters:   p.clusters,
+
+		updateReg: p.updateReg,
+		templates: p.templates,
+		overrides: p.overrides,
 	}
 }
 
@@ -121,16 +186,29 @@ func (p *Tagger) Run() {
 		if length == 0 {
 			continue
 		}
-		for i, a := range s {
-			tag, ok := p.shortcut(a.Lexeme)
-			if !ok {
-				sf, tf := getFeatures(s, i)
-				tag = p.perceptron.predict(sf, tf)
-			}
+		p.Tag(s)
+		p.Output <- s
+	}
+}
 
-			p.setTag(a, tag)
+// Tag tags an AnnotatedSentence in place. Any tags the sentence already has are replaced.
+// Unlike Run, it doesn't need a lexer, so it can be used to tag sentences that come from a treebank.
+func (p *Tagger) Tag(s lingo.AnnotatedSentence) {
+	for _, a := range s {
+		if a == lingo.RootAnnotation() {
+			continue
 		}
-		p.Output <- s
+		a.POSTag = lingo.X
+	}
+
+	for i, a := range s {
+		tag, ok := p.shortcut(a.Lexeme)
+		if !ok {
+			sf, tf := p.features(s, i, &p.buf)
+			tag = p.perceptron.predictAmong(sf, tf, p.candidates(a))
+		}
+
+		p.setTag(a, tag)
 	}
 }
 
@@ -166,8 +244,8 @@ func (p *Tagger) Progress() <-chan Progress {
 	return p.progress
 }
 
-// Train trains a POSTagger, given a bunch of SentenceTags
-func (p *Tagger) Train(sentences []treebank.SentenceTag, iterations int) {
+// Train trains a POSTagger, given a bunch of SentenceTags. It returns an error if the feature templates of the model are invalid.
+func (p *Tagger) Train(sentences []treebank.SentenceTag, iterations int) error {
 	if p.progress != nil {
 		defer func() {
 			close(p.progress)
@@ -175,8 +253,62 @@ func (p *Tagger) Train(sentences []treebank.SentenceTag, iterations int) {
 		}()
 	}
 
-	p.fillCache(sentences)
+	if err := checkFeatureTemplates(p.Model.templates); err != nil {
+		return err
+	}
+
+	counts := countTags(sentences)
+	p.tagCounts = counts
+	p.fillCache(counts)
 
+	p.guesser = newGuesser(p.Model.config.GuesserMaxSuffix)
+	p.guesser.train(sentences, counts, p.Model.config.GuesserRareFreq)
+
+	p.train(sentences, iterations, nil)
+	return nil
+}
+
+// Update continues training an already trained model with new sentences, for the given number of epochs.
+// Unlike Train, the model is not started afresh - the weights and the averaging state are kept, so
+// Update can be called as often as new data comes in (for example, corrections from annotators).
+//
+// Cached tags that disagree with the new sentences are dropped so the new data can be learned.
+// The new sentences are added to the tag dictionary, and their rare words to the unknown word guesser.
+// If the Tagger was created with WithUpdateRegularization, the weights are pulled back towards the
+// weights the model had before Update was called.
+func (p *Tagger) Update(sentences []treebank.SentenceTag, epochs int) {
+	if p.progress != nil {
+		defer func() {
+			close(p.progress)
+			p.progress = nil
+		}()
+	}
+
+	p.uncache(sentences)
+
+	if p.tagCounts == nil {
+		p.tagCounts = make(map[string]map[lingo.POSTag]int)
+	}
+	counts := countTags(sentences)
+	for word, tagCounter := range counts {
+		if _, ok := p.tagCounts[word]; !ok {
+			p.tagCounts[word] = make(map[lingo.POSTag]int)
+		}
+		for t, c := range tagCounter {
+			p.tagCounts[word][t] += c
+		}
+	}
+	p.guesser.train(sentences, p.tagCounts, p.Model.config.GuesserRareFreq)
+
+	var anchor *perceptron
+	if p.updateReg > 0 {
+		anchor = p.perceptron.clone()
+	}
+	p.train(sentences, epochs, anchor)
+}
+
+// train does the actual training of the perceptron. If an anchor is passed in, the weights are regularized towards the anchor after every epoch.
+func (p *Tagger) train(sentences []treebank.SentenceTag, iterations int, anchor *perceptron) {
 	// Somehow sentenceTag.AnnotatedSentence() is memory leaky.
 	// As a result, the more training iterations there is, the more memory is used and not released
 	// hence the cache is necessary.
@@ -215,8 +347,8 @@ func (p *Tagger) Train(sentences []treebank.SentenceTag, iterations int) {
 
 				guess, ok := p.shortcut(a.Lexeme)
 				if !ok {
-					sf, tf := getFeatures(s, i)
-					guess = p.perceptron.predict(sf, tf)
+					sf, tf := p.features(s, i, &p.buf)
+					guess = p.perceptron.predictAmong(sf, tf, p.candidates(a))
 					p.perceptron.update(guess, truth, sf, tf)
 				} else {
 					shortcutted++
@@ -230,6 +362,10 @@ func (p *Tagger) Train(sentences []treebank.SentenceTag, iterations int) {
 			}
 		}
 
+		if anchor != nil {
+			p.perceptron.regularize(anchor, p.updateReg)
+		}
+
 		if iter%150 == 0 {
 			p.perceptron.average()
 			logf("Averaged perceptron")
@@ -239,7 +375,11 @@ func (p *Tagger) Train(sentences []treebank.SentenceTag, iterations int) {
 			p.progress <- Progress{Iter: iter, Correct: c, Count: n, ShortCutted: shortcutted}
 		}
 
-		treebank.ShuffleSentenceTag(sentences)
+		if p.rng != nil {
+			treebank.ShuffleSentenceTagWith(sentences, p.rng)
+		} else {
+			treebank.ShuffleSentenceTag(sentences)
+		}
 	}
 	p.perceptron.average()
 }
@@ -251,9 +391,8 @@ func (p *Tagger) LoadShortcuts(shortcuts map[string]lingo.POSTag) {
 	}
 }
 
-func (p *Tagger) fillCache(sentences []treebank.SentenceTag) {
-	logf("Filling Cache with %d sentences", len(sentences))
-
+// countTags counts how many times each word is tagged with each tag in the sentences
+func countTags(sentences []treebank.SentenceTag) map[string]map[lingo.POSTag]int {
 	var counter = make(map[string]map[lingo.POSTag]int)
 
 	for _, sentenceTag := range sentences {
@@ -271,9 +410,11 @@ func (p *Tagger) fillCache(sentences []treebank.SentenceTag) {
 			counter[w][t]++
 		}
 	}
+	return counter
+}
 
-	freqThresh := 30
-	ambiguityThresh := 0.98
+func (p *Tagger) fillCache(counter map[string]map[lingo.POSTag]int) {
+	logf("Filling Cache with %d words", len(counter))
 
 	for word, tagCounter := range counter {
 		var maxTag lingo.POSTag
@@ -287,16 +428,27 @@ func (p *Tagger) fillCache(sentences []treebank.SentenceTag) {
 			n += c
 		}
 
-		if n >= freqThresh && float64(max)/float64(n) >= ambiguityThresh {
+		if n >= p.Model.config.ShortcutFreq && float64(max)/float64(n) >= p.Model.config.ShortcutAmbiguity {
 			p.cachedTags[word] = maxTag
 		}
 	}
 }
 
-func (p *Tagger) shortcut(l lingo.Lexeme) (lingo.POSTag, bool) {
+// uncache removes the cached tags of words that have been tagged differently in the sentences
+func (p *Tagger) uncache(sentences []treebank.SentenceTag) {
+	for _, sentenceTag := range sentences {
+		for i, lex := range sentenceTag.Sentence {
+			if tag, ok := p.cachedTags[lex.Value]; ok && tag != sentenceTag.Tags[i] {
+				delete(p.cachedTags, lex.Value)
+			}
+		}
+	}
+}
+
+func (m *Model) shortcut(l lingo.Lexeme) (lingo.POSTag, bool) {
 	tag, ok := lingo.POSTagShortcut(l)
 	if !ok {
-		tag, ok = p.cachedTags[l.Value]
+		tag, ok = m.cachedTags[l.Value]
 	}
 	return tag, ok
 }
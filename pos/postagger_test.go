@@ -0,0 +1,114 @@
+package pos
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+)
+
+// distance is the L1 distance between the weights of two perceptrons
+func distance(a, b *perceptron) float64 {
+	var retVal float64
+	for f, weights := range a.weightsSF {
+		orig := b.weightsSF[f]
+		for c, w := range weights {
+			var o float64
+			if orig != nil {
+				o = orig[c]
+			}
+			retVal += math.Abs(w - o)
+		}
+	}
+	for f, weights := range a.weightsTF {
+		orig := b.weightsTF[f]
+		for c, w := range weights {
+			var o float64
+			if orig != nil {
+				o = orig[c]
+			}
+			retVal += math.Abs(w - o)
+		}
+	}
+	return retVal
+}
+
+func TestTagger_Update(t *testing.T) {
+	sentences := treebank.ReadConllu(strings.NewReader(conllu))
+	old, fresh := sentences[:2], sentences[2:]
+
+	var dists []float64
+	for _, reg := range []float64{0, 0.9} {
+		p := New(WithCluster(clusters), WithUpdateRegularization(reg))
+		p.Train(old, 20)
+
+		seen := p.instancesSeen
+		totals := len(p.totals)
+		orig := p.perceptron.clone()
+
+		p.Update(fresh, 20)
+
+		if p.instancesSeen <= seen {
+			t.Errorf("Expected instancesSeen to keep increasing from %v. Got %v", seen, p.instancesSeen)
+		}
+		if len(p.totals) < totals {
+			t.Errorf("Expected the averaging state to be kept. Had %d totals, now %d", totals, len(p.totals))
+		}
+		dists = append(dists, distance(p.perceptron, orig))
+	}
+
+	if dists[1] >= dists[0] {
+		t.Errorf("Expected regularized updates to stay closer to the original weights. Unregularized: %v, Regularized: %v", dists[0], dists[1])
+	}
+}
+
+func TestTagger_Tag(t *testing.T) {
+	sentences := treebank.ReadConllu(strings.NewReader(conllu))
+	p := New(WithCluster(clusters))
+	p.Train(sentences, 20)
+
+	var correct, count int
+	for _, st := range sentences {
+		s := st.AnnotatedSentence(p)
+		for _, a := range s[1:] {
+			a.POSTag = lingo.UNKNOWN_TAG // existing tags must not leak into the features
+		}
+		p.Tag(s)
+
+		if s[0].POSTag != lingo.ROOT_TAG {
+			t.Errorf("Expected the root to be left alone. Got %v", s[0].POSTag)
+		}
+		for i, a := range s[1:] {
+			if a.POSTag == st.Tags[i] {
+				correct++
+			}
+			count++
+		}
+	}
+	if acc := float64(correct) / float64(count); acc < 0.9 {
+		t.Errorf("Expected the training sentences to be tagged mostly correctly. Got %v", acc)
+	}
+}
+
+func TestTagger_WithSeed(t *testing.T) {
+	train := func(seed int64) *perceptron {
+		sentences := treebank.ReadConllu(strings.NewReader(conllu))
+		p := New(WithCluster(clusters), WithSeed(seed))
+		p.Train(sentences, 10)
+		return p.perceptron
+	}
+
+	a, b := train(1337), train(1337)
+	if d := distance(a, b); d != 0 {
+		t.Errorf("Expected training with the same seed to give the same weights. The weights are %v apart", d)
+	}
+	if a.instancesSeen != b.instancesSeen {
+		t.Errorf("Expected the same number of instances seen. Got %v and %v", a.instancesSeen, b.instancesSeen)
+	}
+
+	if d := distance(a, train(1)); d == 0 {
+		t.Error("Expected training with a different seed to give different weights")
+	}
+}
@@ -0,0 +1,274 @@
+package pos
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chewxy/lingo"
+	"github.com/pkg/errors"
+)
+
+/*
+Feature templates allow the features of the tagger to be defined as data, instead of being fixed by the package.
+
+A feature template is a list of parts. Each part describes an attribute of a word at an offset from the word being tagged.
+A template with no parts is the bias feature. A template with more than one part is a conjunction of the parts.
+
+Feature templates can also be written as text, one template per line (or separated by spaces):
+	bias
+	word[0]
+	suffix:3[0]
+	pos[-1]+pos[-2]
+
+The attributes are:
+	word    - the lowercased word
+	lemma   - the lemma
+	cluster - the brown cluster. cluster:N uses the first N bits of the cluster
+	shape   - the shape of the word
+	prefix  - prefix:N is the first N characters of the word
+	suffix  - suffix:N is the last N characters of the lowercased word
+	pos     - the POSTag
+	flags   - the word flags
+*/
+
+// Attribute is an attribute of a word that a feature template extracts
+type Attribute byte
+
+const (
+	WordAttr Attribute = iota
+	LemmaAttr
+	ClusterAttr
+	ShapeAttr
+	PrefixAttr
+	SuffixAttr
+	POSTagAttr
+	FlagsAttr
+
+	MAXATTRIBUTE
+)
+
+var attributeNames = [MAXATTRIBUTE]string{"word", "lemma", "cluster", "shape", "prefix", "suffix", "pos", "flags"}
+
+func (a Attribute) String() string {
+	if a >= MAXATTRIBUTE {
+		return fmt.Sprintf("Attribute(%d)", a)
+	}
+	return attributeNames[a]
+}
+
+// FeaturePart is a part of a feature template. It describes the attribute of the word at Offset from the word being tagged.
+// N is only used by the prefix, suffix and cluster attributes.
+type FeaturePart struct {
+	Offset int
+	Attribute
+	N int
+}
+
+func (fp FeaturePart) String() string {
+	if fp.N > 0 {
+		return fmt.Sprintf("%v:%d[%d]", fp.Attribute, fp.N, fp.Offset)
+	}
+	return fmt.Sprintf("%v[%d]", fp.Attribute, fp.Offset)
+}
+
+// FeatureTemplate is a template for a feature. A template with more than one part is a conjunction.
+type FeatureTemplate []FeaturePart
+
+func (ft FeatureTemplate) String() string {
+	if len(ft) == 0 {
+		return "bias"
+	}
+
+	var buf bytes.Buffer
+	for i, part := range ft {
+		if i > 0 {
+			buf.WriteString("+")
+		}
+		buf.WriteString(part.String())
+	}
+	return buf.String()
+}
+
+// MAXTEMPLATES is the maximum number of feature templates a model can have
+const MAXTEMPLATES = 256 - int(MAXFEATURETYPE)
+
+// DefaultFeatureTemplates returns the feature templates that describe the features the tagger uses when no templates are provided.
+// It is a good starting point for experimenting with feature templates.
+func DefaultFeatureTemplates() []FeatureTemplate {
+	return []FeatureTemplate{
+		nil, // bias
+		{{0, WordAttr, 0}},
+		{{1, WordAttr, 0}},
+		{{2, WordAttr, 0}},
+
+		{{0, SuffixAttr, 3}},
+		{{0, PrefixAttr, 1}},
+
+		{{-1, POSTagAttr, 0}},
+		{{-2, POSTagAttr, 0}},
+		{{-1, SuffixAttr, 3}},
+		{{1, SuffixAttr, 3}},
+
+		{{0, ShapeAttr, 0}},
+		{{0, ClusterAttr, 0}},
+		{{1, ClusterAttr, 0}},
+		{{2, ClusterAttr, 0}},
+		{{-1, ClusterAttr, 0}},
+		{{-2, ClusterAttr, 0}},
+
+		{{0, FlagsAttr, 0}},
+		{{1, FlagsAttr, 0}},
+		{{2, FlagsAttr, 0}},
+		{{-1, FlagsAttr, 0}},
+		{{-2, FlagsAttr, 0}},
+
+		{{-1, LemmaAttr, 0}, {-1, POSTagAttr, 0}},
+		{{-1, POSTagAttr, 0}, {0, WordAttr, 0}},
+		{{-1, POSTagAttr, 0}, {-2, POSTagAttr, 0}},
+		{{-2, LemmaAttr, 0}, {-2, POSTagAttr, 0}},
+	}
+}
+
+// ParseFeatureTemplates parses feature templates written in text. Lines starting with # are comments.
+func ParseFeatureTemplates(s string) ([]FeatureTemplate, error) {
+	var retVal []FeatureTemplate
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, field := range strings.Fields(line) {
+			ft, err := parseFeatureTemplate(field)
+			if err != nil {
+				return nil, err
+			}
+			retVal = append(retVal, ft)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := checkFeatureTemplates(retVal); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// checkFeatureTemplates checks that there aren't more feature templates than a model can have
+func checkFeatureTemplates(templates []FeatureTemplate) error {
+	if len(templates) > MAXTEMPLATES {
+		return errors.Errorf("Too many feature templates: %d. A model can have at most %d feature templates", len(templates), MAXTEMPLATES)
+	}
+	return nil
+}
+
+func parseFeatureTemplate(s string) (FeatureTemplate, error) {
+	if s == "bias" {
+		return nil, nil
+	}
+
+	var retVal FeatureTemplate
+	for _, p := range strings.Split(s, "+") {
+		open := strings.Index(p, "[")
+		if open < 0 || !strings.HasSuffix(p, "]") {
+			return nil, errors.Errorf("Unable to parse %q in feature template %q. Expected attribute[offset]", p, s)
+		}
+
+		var part FeaturePart
+		var err error
+		if part.Offset, err = strconv.Atoi(p[open+1 : len(p)-1]); err != nil {
+			return nil, errors.Wrapf(err, "Unable to parse offset of %q in feature template %q", p, s)
+		}
+
+		name := p[:open]
+		if colon := strings.Index(name, ":"); colon >= 0 {
+			if part.N, err = strconv.Atoi(name[colon+1:]); err != nil || part.N <= 0 {
+				return nil, errors.Errorf("Unable to parse length of %q in feature template %q", p, s)
+			}
+			name = name[:colon]
+		}
+
+		part.Attribute = MAXATTRIBUTE
+		for i, n := range attributeNames {
+			if n == name {
+				part.Attribute = Attribute(i)
+			}
+		}
+
+		switch part.Attribute {
+		case MAXATTRIBUTE:
+			return nil, errors.Errorf("Unknown attribute %q in feature template %q", name, s)
+		case PrefixAttr, SuffixAttr:
+			if part.N == 0 {
+				return nil, errors.Errorf("%v requires a length in feature template %q", part.Attribute, s)
+			}
+		}
+		retVal = append(retVal, part)
+	}
+	return retVal, nil
+}
+
+// attribute extracts the attribute from the annotation
+func attribute(a *lingo.Annotation, attr Attribute, n int) string {
+	switch attr {
+	case WordAttr:
+		return a.Lowered
+	case LemmaAttr:
+		return a.Lemma
+	case ClusterAttr:
+		if n > 0 {
+			bits := strconv.FormatInt(int64(a.Cluster), 2)
+			if len(bits) > n {
+				bits = bits[:n]
+			}
+			return bits
+		}
+		return strconv.Itoa(int(a.Cluster))
+	case ShapeAttr:
+		return string(a.Shape)
+	case PrefixAttr:
+		asRunes := []rune(a.Value)
+		if len(asRunes) > n {
+			asRunes = asRunes[:n]
+		}
+		return string(asRunes)
+	case SuffixAttr:
+		loweredRunes := []rune(a.Lowered)
+		if len(loweredRunes) >= n {
+			return string(loweredRunes[len(loweredRunes)-n:])
+		}
+		return ""
+	case POSTagAttr:
+		return a.POSTag.String()
+	case FlagsAttr:
+		return a.WordFlag.String()
+	}
+	panic(fmt.Sprintf("Unknown attribute %v", attr))
+}
+
+// templateFeatures extracts the features of the ith word of the sentence using the templates, and appends them to sf.
+// Each template is given its own featureType, starting from MAXFEATURETYPE, and conjunctions are joined into one value.
+func templateFeatures(templates []FeatureTemplate, s lingo.AnnotatedSentence, i int, sf []singleFeature) []singleFeature {
+	var buf bytes.Buffer
+	for j, ft := range templates {
+		buf.Reset()
+		for k, part := range ft {
+			if k > 0 {
+				buf.WriteByte('\x1f')
+			}
+
+			a := lingo.NullAnnotation()
+			if i+part.Offset >= 0 && i+part.Offset < len(s) {
+				a = s[i+part.Offset]
+			}
+			buf.WriteString(attribute(a, part.Attribute, part.N))
+		}
+		sf = append(sf, singleFeature{MAXFEATURETYPE + featureType(j), buf.String()})
+	}
+	return sf
+}
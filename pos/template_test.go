@@ -0,0 +1,123 @@
+package pos
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chewxy/lingo"
+	"github.com/chewxy/lingo/treebank"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeatureTemplates(t *testing.T) {
+	assert := assert.New(t)
+
+	var lines []string
+	for _, ft := range DefaultFeatureTemplates() {
+		lines = append(lines, ft.String())
+	}
+
+	templates, err := ParseFeatureTemplates("# the default templates\n" + strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(DefaultFeatureTemplates(), templates)
+
+	templates, err = ParseFeatureTemplates("bias word[0] cluster:4[-1]+suffix:2[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	correct := []FeatureTemplate{
+		nil,
+		{{0, WordAttr, 0}},
+		{{-1, ClusterAttr, 4}, {1, SuffixAttr, 2}},
+	}
+	assert.Equal(correct, templates)
+
+	bad := []string{"word", "words[0]", "suffix[0]", "word[a]", "prefix:-1[0]"}
+	for _, b := range bad {
+		if _, err := ParseFeatureTemplates(b); err == nil {
+			t.Errorf("Expected an error when parsing %q", b)
+		}
+	}
+
+	if _, err := ParseFeatureTemplates(strings.Repeat("bias ", MAXTEMPLATES+1)); err == nil {
+		t.Errorf("Expected an error when there are more than %d templates", MAXTEMPLATES)
+	}
+	tooMany := make([]FeatureTemplate, MAXTEMPLATES+1)
+	if err := New(WithFeatureTemplates(tooMany...)).Train(nil, 1); err == nil {
+		t.Errorf("Expected Train to return an error when there are more than %d templates", MAXTEMPLATES)
+	}
+}
+
+// TestDefaultFeatureTemplates checks that the default templates extract the same values as the compiled features
+func TestDefaultFeatureTemplates(t *testing.T) {
+	sentences := treebank.ReadConllu(strings.NewReader(conllu))
+	templates := DefaultFeatureTemplates()
+	for _, st := range sentences {
+		s := st.AnnotatedSentence(dummyFix{})
+		for i := range s {
+			sf, tf := getFeatures(s, i)
+			tsf := templateFeatures(templates, s, i, nil)
+
+			// bias is skipped: the compiled bias feature is not constant
+			for j := 1; j < len(sf); j++ {
+				if sf[j].value != tsf[j].value {
+					t.Errorf("%v of word %d: Want %q. Got %q", templates[j], i, sf[j].value, tsf[j].value)
+				}
+			}
+			for j, f := range tf {
+				want := f.value1 + "\x1f" + f.value2
+				if got := tsf[len(sf)+j].value; want != got {
+					t.Errorf("%v of word %d: Want %q. Got %q", templates[len(sf)+j], i, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestTagger_FeatureTemplates(t *testing.T) {
+	templates, err := ParseFeatureTemplates(`bias word[0] word[-1] word[1] suffix:2[0] pos[-1] pos[-1]+word[0] shape[0]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sentences := treebank.ReadConllu(strings.NewReader(conllu))
+	p := New(WithFeatureTemplates(templates...))
+	p.Train(sentences, 50)
+
+	var correct, count int
+	for _, st := range sentences {
+		s := st.AnnotatedSentence(dummyFix{})
+		for _, a := range s[1:] {
+			a.POSTag = lingo.X
+		}
+		for i, a := range s[1:] {
+			tag, ok := p.shortcut(a.Lexeme)
+			if !ok {
+				sf, tf := p.features(s, i+1, &p.buf)
+				tag = p.perceptron.predict(sf, tf)
+			}
+			if tag == st.Tags[i] {
+				correct++
+			}
+			a.POSTag = tag
+			count++
+		}
+	}
+	if float64(correct)/float64(count) < 0.9 {
+		t.Errorf("Expected the training set to be learned. Got %d/%d correct", correct, count)
+	}
+
+	if err := p.Save("templates.dat"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove("templates.dat")
+
+	m, err := Load("templates.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, templates, m.FeatureTemplates())
+}
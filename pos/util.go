@@ -18,3 +18,21 @@ func maxScore(scores *[lingo.MAXTAG]float64) lingo.POSTag {
 
 	return maxClass
 }
+
+// maxScoreAmong is like maxScore, but only the allowed classes are considered. If allowed is nil, all classes are considered.
+func maxScoreAmong(scores *[lingo.MAXTAG]float64, allowed *[lingo.MAXTAG]bool) lingo.POSTag {
+	if allowed == nil {
+		return maxScore(scores)
+	}
+
+	var maxClass lingo.POSTag
+	maxVal := -math.MaxFloat64
+	for c, v := range scores {
+		if allowed[c] && v > maxVal {
+			maxClass = lingo.POSTag(c)
+			maxVal = v
+		}
+	}
+
+	return maxClass
+}
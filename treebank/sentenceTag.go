@@ -56,10 +56,16 @@ func (s SentenceTag) String() string {
 	return s.Sentence.String()
 }
 
+// ShuffleSentenceTag shuffles the SentenceTags in place. The shuffle is seeded with the same seed every time, so it always
+// shuffles a slice of the same length the same way. Use ShuffleSentenceTagWith to shuffle with random numbers of your own.
 func ShuffleSentenceTag(s []SentenceTag) []SentenceTag {
-	rand.Seed(1337)
+	return ShuffleSentenceTagWith(s, rand.New(rand.NewSource(1337)))
+}
+
+// ShuffleSentenceTagWith shuffles the SentenceTags in place, with the random numbers from r.
+func ShuffleSentenceTagWith(s []SentenceTag, r *rand.Rand) []SentenceTag {
 	for i := range s {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		s[i], s[j] = s[j], s[i]
 	}
 